@@ -0,0 +1,251 @@
+package chipotle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the API while an
+// endpoint's circuit breaker is open.
+var ErrCircuitOpen = errors.New("chipotle: circuit open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through while counting failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails requests fast until the open interval passes.
+	CircuitOpen
+	// CircuitHalfOpen lets a few probe requests through to decide
+	// whether to close the circuit again.
+	CircuitHalfOpen
+)
+
+// String implements fmt.Stringer.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+
+	return "unknown"
+}
+
+// CircuitBreakerSettings configures the circuit breakers created by
+// WithCircuitBreaker.
+type CircuitBreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens
+	// the circuit.
+	FailureThreshold int
+	// OpenInterval is how long the circuit stays open before probing.
+	OpenInterval time.Duration
+	// HalfOpenProbes is the number of probes let through at once while
+	// half-open, and the number of successes needed to close the circuit.
+	HalfOpenProbes int
+	// IsFailure reports whether the outcome of an attempt counts as a
+	// failure. resp is nil when err is set. The default counts transport
+	// errors, 429s and 5xx statuses.
+	IsFailure func(resp *http.Response, err error) bool
+}
+
+// DefaultCircuitBreakerSettings returns settings that open after ten
+// consecutive failures and probe with one request after 30 seconds.
+func DefaultCircuitBreakerSettings() CircuitBreakerSettings {
+	return CircuitBreakerSettings{
+		FailureThreshold: 10,
+		OpenInterval:     30 * time.Second,
+		HalfOpenProbes:   1,
+	}
+}
+
+// WithCircuitBreaker gives every endpoint its own circuit breaker, so
+// requests fail fast with ErrCircuitOpen while the upstream is failing.
+func WithCircuitBreaker(settings CircuitBreakerSettings) Option {
+	return func(c *Client) {
+		c.breakers = map[Endpoint]*CircuitBreaker{
+			EndpointSearch: NewCircuitBreaker(settings),
+			EndpointMenu:   NewCircuitBreaker(settings),
+		}
+	}
+}
+
+// CircuitBreaker returns the circuit breaker guarding endpoint, or nil if
+// the client has none.
+func (c *Client) CircuitBreaker(endpoint Endpoint) *CircuitBreaker {
+	return c.breakers[endpoint]
+}
+
+// CircuitBreaker tracks the health of an endpoint. It is safe for
+// concurrent use. A nil *CircuitBreaker always allows requests.
+type CircuitBreaker struct {
+	settings CircuitBreakerSettings
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+	// generation changes with every state transition, so outcomes of
+	// attempts allowed in an earlier state are ignored.
+	generation uint64
+}
+
+// breakerTicket identifies an attempt allowed by a circuit breaker.
+type breakerTicket struct {
+	generation uint64
+	probe      bool
+}
+
+// NewCircuitBreaker returns a closed circuit breaker.
+func NewCircuitBreaker(settings CircuitBreakerSettings) *CircuitBreaker {
+	settings.FailureThreshold = max(settings.FailureThreshold, 1)
+	settings.HalfOpenProbes = max(settings.HalfOpenProbes, 1)
+	if settings.IsFailure == nil {
+		settings.IsFailure = isUpstreamFailure
+	}
+
+	return &CircuitBreaker{settings: settings}
+}
+
+// State returns the current state of the circuit, for health checks.
+func (b *CircuitBreaker) State() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(time.Now())
+
+	return b.state
+}
+
+// allow reports whether an attempt may proceed, returning ErrCircuitOpen
+// if not. Every allowed attempt must be followed by record or release with
+// the returned ticket.
+func (b *CircuitBreaker) allow() (breakerTicket, error) {
+	if b == nil {
+		return breakerTicket{}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance(time.Now())
+
+	ticket := breakerTicket{generation: b.generation}
+	switch b.state {
+	case CircuitOpen:
+		return breakerTicket{}, ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.settings.HalfOpenProbes {
+			return breakerTicket{}, ErrCircuitOpen
+		}
+		b.probes++
+		ticket.probe = true
+	}
+
+	return ticket, nil
+}
+
+// record counts the outcome of an allowed attempt. Attempts abandoned
+// because ctx was cancelled don't count either way, and neither do attempts
+// allowed before the circuit last changed state.
+func (b *CircuitBreaker) record(ctx context.Context, ticket breakerTicket, resp *http.Response, err error) {
+	if b == nil {
+		return
+	}
+
+	if err != nil && ctx.Err() != nil {
+		b.release(ticket)
+		return
+	}
+
+	failed := b.settings.IsFailure(resp, err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ticket.generation != b.generation {
+		return
+	}
+
+	switch b.state {
+	case CircuitClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+
+		b.failures++
+		if b.failures >= b.settings.FailureThreshold {
+			b.open()
+		}
+
+	case CircuitHalfOpen:
+		b.probes--
+		if failed {
+			b.open()
+			return
+		}
+
+		b.successes++
+		if b.successes >= b.settings.HalfOpenProbes {
+			b.state = CircuitClosed
+			b.failures = 0
+			b.generation++
+		}
+	}
+}
+
+// release gives back an allowed attempt that was never sent.
+func (b *CircuitBreaker) release(ticket breakerTicket) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if ticket.probe && ticket.generation == b.generation {
+		b.probes--
+	}
+}
+
+// open trips the circuit. b.mu must be held.
+func (b *CircuitBreaker) open() {
+	b.state = CircuitOpen
+	b.openedAt = time.Now()
+	b.generation++
+}
+
+// advance moves an open circuit to half-open once the open interval has
+// passed. b.mu must be held.
+func (b *CircuitBreaker) advance(now time.Time) {
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.settings.OpenInterval {
+		b.state = CircuitHalfOpen
+		b.probes = 0
+		b.successes = 0
+		b.generation++
+	}
+}
+
+// isUpstreamFailure counts transport errors, throttling and server errors
+// as failures; other statuses mean the upstream is answering.
+func isUpstreamFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,146 @@
+package chipotle_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	const openInterval = 50 * time.Millisecond
+
+	// step is one call to the menu endpoint, after waiting wait, which
+	// must leave an open circuit half-open
+	type step struct {
+		wait         time.Duration
+		fail         bool
+		wantErr      error
+		wantState    chipotle.CircuitState
+		wantRequests int
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "open, half-open, closed",
+			steps: []step{
+				{fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitClosed, wantRequests: 1},
+				{fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitOpen, wantRequests: 2},
+				{wantErr: chipotle.ErrCircuitOpen, wantState: chipotle.CircuitOpen, wantRequests: 2},
+				{wait: openInterval, wantState: chipotle.CircuitClosed, wantRequests: 3},
+				{fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitClosed, wantRequests: 4},
+			},
+		},
+		{
+			name: "failed probe reopens",
+			steps: []step{
+				{fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitClosed, wantRequests: 1},
+				{fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitOpen, wantRequests: 2},
+				{wait: openInterval, fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitOpen, wantRequests: 3},
+				{wantErr: chipotle.ErrCircuitOpen, wantState: chipotle.CircuitOpen, wantRequests: 3},
+			},
+		},
+		{
+			name: "success resets failures",
+			steps: []step{
+				{fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitClosed, wantRequests: 1},
+				{wantState: chipotle.CircuitClosed, wantRequests: 2},
+				{fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitClosed, wantRequests: 3},
+			},
+		},
+		{
+			name: "client errors don't count",
+			steps: []step{
+				{fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitClosed, wantRequests: 1},
+				{wantErr: chipotle.ErrNotFound, wantState: chipotle.CircuitClosed, wantRequests: 2},
+				{fail: true, wantErr: chipotle.ErrRateLimited, wantState: chipotle.CircuitClosed, wantRequests: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 1)
+			c := newClient(s,
+				chipotle.WithRetryPolicy(chipotle.RetryPolicy{}),
+				chipotle.WithCircuitBreaker(chipotle.CircuitBreakerSettings{
+					FailureThreshold: 2,
+					OpenInterval:     openInterval,
+					HalfOpenProbes:   1,
+				}),
+			)
+			breaker := c.CircuitBreaker(chipotle.EndpointMenu)
+
+			for i, step := range tt.steps {
+				if step.wait > 0 {
+					time.Sleep(step.wait)
+					if got := breaker.State(); got != chipotle.CircuitHalfOpen {
+						t.Errorf("step %d: State() after the open interval = %v, want %v", i, got, chipotle.CircuitHalfOpen)
+					}
+				}
+				if step.fail {
+					s.InjectFault(chipotletest.Fault{Endpoint: chipotle.EndpointMenu, StatusCode: http.StatusTooManyRequests, Times: 1})
+				}
+
+				id := 1
+				if errors.Is(step.wantErr, chipotle.ErrNotFound) {
+					id = 2
+				}
+
+				_, err := c.GetMenuContext(context.Background(), id)
+				if !errors.Is(err, step.wantErr) {
+					t.Fatalf("step %d: GetMenuContext() error = %v, want %v", i, err, step.wantErr)
+				}
+				if got := breaker.State(); got != step.wantState {
+					t.Errorf("step %d: State() = %v, want %v", i, got, step.wantState)
+				}
+				if got := s.Requests(chipotle.EndpointMenu); got != step.wantRequests {
+					t.Errorf("step %d: requests = %d, want %d", i, got, step.wantRequests)
+				}
+			}
+
+			// the search endpoint has its own breaker
+			if got := c.CircuitBreaker(chipotle.EndpointSearch).State(); got != chipotle.CircuitClosed {
+				t.Errorf("search State() = %v, want %v", got, chipotle.CircuitClosed)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerIgnoresStaleOutcomes(t *testing.T) {
+	ok := &http.Response{StatusCode: http.StatusOK}
+	failed := &http.Response{StatusCode: http.StatusInternalServerError}
+
+	b := chipotle.NewCircuitBreaker(chipotle.CircuitBreakerSettings{
+		FailureThreshold: 1,
+		OpenInterval:     time.Hour,
+	})
+
+	// two attempts go out while closed; the first one's failure opens the
+	// circuit, and the second one's success must not close it again
+	first, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	second, err := b.Allow()
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+
+	b.Record(first, failed, nil)
+	b.Record(second, ok, nil)
+
+	if got := b.State(); got != chipotle.CircuitOpen {
+		t.Errorf("State() = %v, want %v", got, chipotle.CircuitOpen)
+	}
+	if _, err := b.Allow(); !errors.Is(err, chipotle.ErrCircuitOpen) {
+		t.Errorf("Allow() error = %v, want %v", err, chipotle.ErrCircuitOpen)
+	}
+}
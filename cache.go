@@ -0,0 +1,106 @@
+package chipotle
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// DefaultCacheTTL is how long responses are cached for endpoints without a
+// TTL set by WithCacheTTL.
+const DefaultCacheTTL = 15 * time.Minute
+
+// Cache stores raw response bodies. Implementations must be safe for
+// concurrent use. See NewMemoryCache and NewDiskCache.
+type Cache interface {
+	// Get returns the value stored under key, if present and not expired.
+	Get(key string) ([]byte, bool)
+	// Set stores value under key for ttl.
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete removes the value stored under key.
+	Delete(key string)
+	// DeletePrefix removes every value whose key starts with prefix.
+	DeletePrefix(prefix string)
+}
+
+// WithCache caches successful responses in cache. Every endpoint is cached
+// for DefaultCacheTTL unless overridden with WithCacheTTL.
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// WithCacheTTL sets how long responses from endpoint are cached. A
+// non-positive ttl disables caching for the endpoint.
+func WithCacheTTL(endpoint Endpoint, ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cacheTTLs[endpoint] = ttl
+	}
+}
+
+type bypassCacheKey struct{}
+
+// BypassCache returns a context that makes requests skip the cache lookup.
+// Fresh responses are still stored, so this also refreshes stale entries.
+func BypassCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+// InvalidateMenu removes every cached menu of the restaurant, and forgets
+// its validators so the next request is unconditional.
+func (c *Client) InvalidateMenu(restaurantID int) {
+	c.validators.forget(menuPath(restaurantID))
+
+	if c.cache == nil {
+		return
+	}
+
+	c.cache.DeletePrefix(cacheKey(EndpointMenu, "GET", menuPath(restaurantID), nil))
+}
+
+// cacheTTL returns how long responses from endpoint are cached, or zero if
+// they aren't.
+func (c *Client) cacheTTL(endpoint Endpoint) time.Duration {
+	if c.cache == nil {
+		return 0
+	}
+
+	if ttl, ok := c.cacheTTLs[endpoint]; ok {
+		return ttl
+	}
+
+	return DefaultCacheTTL
+}
+
+// cacheResponse caches the body of resp, the upstream response to req,
+// unless it was served from the cache in the first place.
+func (c *Client) cacheResponse(req *Request, resp *Response) {
+	ttl := c.cacheTTL(req.Endpoint)
+	if ttl <= 0 || resp.Cached {
+		return
+	}
+
+	c.cache.Set(cacheKey(req.Endpoint, req.Method, req.Path, req.Body), resp.Body, ttl)
+}
+
+// cacheKey identifies a request by endpoint, method and path including the
+// query string, plus a hash of the body if there is one. Keys for the same
+// path share a prefix regardless of query, so they can be invalidated
+// together.
+func cacheKey(endpoint Endpoint, method, path string, body []byte) string {
+	key := fmt.Sprintf("%s %s %s", endpoint, method, path)
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		key += " " + hex.EncodeToString(sum[:])
+	}
+
+	return key
+}
@@ -0,0 +1,245 @@
+package chipotle_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+)
+
+// testCache runs the behavior every Cache must have against the caches
+// returned by newCache.
+func testCache(t *testing.T, newCache func(t *testing.T) chipotle.Cache) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		// run acts on the cache and returns the key to look up
+		run       func(c chipotle.Cache) string
+		wantValue string
+		wantOK    bool
+	}{
+		{
+			name:   "missing",
+			run:    func(c chipotle.Cache) string { return "menu /1" },
+			wantOK: false,
+		},
+		{
+			name: "set",
+			run: func(c chipotle.Cache) string {
+				c.Set("menu /1", []byte("one"), time.Minute)
+				return "menu /1"
+			},
+			wantValue: "one",
+			wantOK:    true,
+		},
+		{
+			name: "overwrite",
+			run: func(c chipotle.Cache) string {
+				c.Set("menu /1", []byte("one"), time.Minute)
+				c.Set("menu /1", []byte("uno"), time.Minute)
+				return "menu /1"
+			},
+			wantValue: "uno",
+			wantOK:    true,
+		},
+		{
+			name: "expired",
+			run: func(c chipotle.Cache) string {
+				c.Set("menu /1", []byte("one"), -time.Second)
+				return "menu /1"
+			},
+			wantOK: false,
+		},
+		{
+			name: "delete",
+			run: func(c chipotle.Cache) string {
+				c.Set("menu /1", []byte("one"), time.Minute)
+				c.Delete("menu /1")
+				return "menu /1"
+			},
+			wantOK: false,
+		},
+		{
+			name: "delete prefix",
+			run: func(c chipotle.Cache) string {
+				c.Set("menu /1?channelId=web", []byte("web"), time.Minute)
+				c.Set("menu /1?channelId=kiosk", []byte("kiosk"), time.Minute)
+				c.DeletePrefix("menu /1?")
+				return "menu /1?channelId=kiosk"
+			},
+			wantOK: false,
+		},
+		{
+			name: "delete prefix keeps others",
+			run: func(c chipotle.Cache) string {
+				c.Set("menu /1?channelId=web", []byte("one"), time.Minute)
+				c.Set("menu /12?channelId=web", []byte("twelve"), time.Minute)
+				c.DeletePrefix("menu /1?")
+				return "menu /12?channelId=web"
+			},
+			wantValue: "twelve",
+			wantOK:    true,
+		},
+		{
+			name: "value copied in",
+			run: func(c chipotle.Cache) string {
+				value := []byte("one")
+				c.Set("menu /1", value, time.Minute)
+				copy(value, "two")
+				return "menu /1"
+			},
+			wantValue: "one",
+			wantOK:    true,
+		},
+		{
+			name: "value copied out",
+			run: func(c chipotle.Cache) string {
+				c.Set("menu /1", []byte("one"), time.Minute)
+				if value, ok := c.Get("menu /1"); ok {
+					copy(value, "two")
+				}
+				return "menu /1"
+			},
+			wantValue: "one",
+			wantOK:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCache(t)
+			key := tt.run(c)
+
+			value, ok := c.Get(key)
+			if ok != tt.wantOK || string(value) != tt.wantValue {
+				t.Errorf("Get(%q) = %q, %v, want %q, %v", key, value, ok, tt.wantValue, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	tests := []struct {
+		name string
+		// run fetches menu 1 through c and returns whether the last fetch
+		// was served from the cache
+		run          func(t *testing.T, c *chipotle.Client) bool
+		faults       []chipotletest.Fault
+		wantCached   bool
+		wantRequests int
+	}{
+		{
+			name: "cached",
+			run: func(t *testing.T, c *chipotle.Client) bool {
+				getMenu(t, c, context.Background())
+				return getMenu(t, c, context.Background())
+			},
+			wantCached:   true,
+			wantRequests: 1,
+		},
+		{
+			name: "bypassed",
+			run: func(t *testing.T, c *chipotle.Client) bool {
+				getMenu(t, c, context.Background())
+				return getMenu(t, c, chipotle.BypassCache(context.Background()))
+			},
+			wantCached:   false,
+			wantRequests: 2,
+		},
+		{
+			name: "refreshed by bypass",
+			run: func(t *testing.T, c *chipotle.Client) bool {
+				getMenu(t, c, chipotle.BypassCache(context.Background()))
+				return getMenu(t, c, context.Background())
+			},
+			wantCached:   true,
+			wantRequests: 1,
+		},
+		{
+			name: "invalidated",
+			run: func(t *testing.T, c *chipotle.Client) bool {
+				getMenu(t, c, context.Background())
+				c.InvalidateMenu(1)
+				return getMenu(t, c, context.Background())
+			},
+			wantCached:   false,
+			wantRequests: 2,
+		},
+		{
+			name: "truncated response not cached",
+			run: func(t *testing.T, c *chipotle.Client) bool {
+				if _, err := c.GetMenuRaw(context.Background(), 1); err == nil {
+					t.Fatal("GetMenuRaw() error = nil, want a decoding error")
+				}
+				return getMenu(t, c, context.Background())
+			},
+			faults:       []chipotletest.Fault{{Endpoint: chipotle.EndpointMenu, Truncate: true, Times: 1}},
+			wantCached:   false,
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 1)
+			for _, f := range tt.faults {
+				s.InjectFault(f)
+			}
+			c := newClient(s, chipotle.WithCache(chipotle.NewMemoryCache(0)))
+
+			if got := tt.run(t, c); got != tt.wantCached {
+				t.Errorf("cached = %v, want %v", got, tt.wantCached)
+			}
+			if got := s.Requests(chipotle.EndpointMenu); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestWithCacheRawHeaders(t *testing.T) {
+	s := newServer(t, 1)
+	c := newClient(s, chipotle.WithCache(chipotle.NewMemoryCache(0)))
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantCached bool
+	}{
+		{name: "fetched", ctx: context.Background()},
+		// the cache keeps only the body
+		{name: "cached", ctx: context.Background(), wantCached: true},
+		{name: "bypassed", ctx: chipotle.BypassCache(context.Background())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := c.GetMenuRaw(tt.ctx, 1)
+			if err != nil {
+				t.Fatalf("GetMenuRaw() error = %v", err)
+			}
+
+			if resp.Raw.Cached != tt.wantCached {
+				t.Errorf("Cached = %v, want %v", resp.Raw.Cached, tt.wantCached)
+			}
+			if got := resp.Raw.Header.Get("ETag") != ""; got == tt.wantCached {
+				t.Errorf("Header = %v, want headers only when not cached", resp.Raw.Header)
+			}
+		})
+	}
+}
+
+// getMenu fetches menu 1, reporting whether it came from the cache.
+func getMenu(t *testing.T, c *chipotle.Client, ctx context.Context) bool {
+	t.Helper()
+
+	resp, err := c.GetMenuRaw(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetMenuRaw() error = %v", err)
+	}
+
+	return resp.Raw.Cached
+}
@@ -2,20 +2,64 @@ package chipotle
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/kylegrantlucas/chipotle-go/menu"
 	"github.com/kylegrantlucas/chipotle-go/search"
 )
 
+// BASE_URL is the default host used by clients that are not configured
+// with WithBaseURL.
 const BASE_URL = "https://services.chipotle.com"
 
+// Endpoint names one of the upstream API endpoints used by the client.
+type Endpoint string
+
+const (
+	// EndpointSearch is the restaurant search endpoint.
+	EndpointSearch Endpoint = "search"
+	// EndpointMenu is the online menu endpoint.
+	EndpointMenu Endpoint = "menu"
+)
+
 type Client struct {
 	APIKey     string
 	httpClient *http.Client
+
+	baseURL   string
+	transport http.RoundTripper
+	timeout   time.Duration
+	headers   map[string]string
+
+	retryPolicy RetryPolicy
+
+	rateLimiter          *RateLimiter
+	endpointRateLimiters map[Endpoint]*RateLimiter
+
+	cache     Cache
+	cacheTTLs map[Endpoint]time.Duration
+
+	middleware []Middleware
+	tel        *telemetry
+	logger     *slog.Logger
+
+	credentials CredentialProvider
+
+	flights         *flightGroup
+	dedupeAll       bool
+	dedupeEndpoints map[Endpoint]bool
+
+	breakers map[Endpoint]*CircuitBreaker
+
+	drift *driftLog
+
+	validators *validatorStore
 }
 
 // CustomTransport is a custom http.RoundTripper that adds default headers.
@@ -35,122 +79,346 @@ func (c *customTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 
 	// Use the custom transport or fallback to http.DefaultTransport
-	if c.Transport == nil {
-		c.Transport = http.DefaultTransport
+	transport := c.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
 	}
 
-	return c.Transport.RoundTrip(newReq)
+	return transport.RoundTrip(newReq)
 }
 
-func NewClient(apiKey string) *Client {
-	// create http client with api key header and content type set to application/json
-	defaultHeaders := map[string]string{
-		"Content-Type":              "application/json",
-		"Ocp-Apim-Subscription-Key": apiKey,
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		APIKey:  apiKey,
+		baseURL: BASE_URL,
+		// create http client with content type set to application/json, the
+		// api key header is added per request from the credential provider
+		headers: map[string]string{
+			"Content-Type": "application/json",
+		},
+		credentials:          StaticCredentials(apiKey),
+		endpointRateLimiters: map[Endpoint]*RateLimiter{},
+		cacheTTLs:            map[Endpoint]time.Duration{},
+		dedupeEndpoints:      map[Endpoint]bool{},
 	}
 
-	// Create the custom transport
-	customTransport := &customTransport{
-		Headers: defaultHeaders,
+	for _, opt := range opts {
+		opt(c)
 	}
 
-	// Create the http client
-	// Use the custom transport
-	client := &http.Client{
-		Transport: customTransport,
+	// Copy the configured http client, or start from a bare one, so the
+	// transport can be wrapped without touching the caller's client
+	client := &http.Client{}
+	if c.httpClient != nil {
+		*client = *c.httpClient
 	}
 
-	return &Client{
-		APIKey:     apiKey,
-		httpClient: client,
+	if c.timeout > 0 {
+		client.Timeout = c.timeout
 	}
+
+	// An explicit transport wins over the one on the supplied http client
+	transport := c.transport
+	if transport == nil {
+		transport = client.Transport
+	}
+
+	// Create the custom transport
+	client.Transport = &customTransport{
+		Transport: transport,
+		Headers:   c.headers,
+	}
+
+	c.httpClient = client
+
+	return c
 }
 
+// Search runs query against the restaurant search endpoint, following
+// pagination until every page has been fetched. Use SearchPages or
+// SearchRestaurants to stream large searches instead.
 func (c *Client) Search(query search.Query) (*search.Result, error) {
+	return c.SearchContext(context.Background(), query)
+}
+
+// SearchContext is like Search but carries ctx through every page request,
+// so cancelling ctx or hitting its deadline aborts the whole search.
+func (c *Client) SearchContext(ctx context.Context, query search.Query) (*search.Result, error) {
+	var result *search.Result
+
+	pager := c.SearchPages(ctx, query)
+	for pager.Next() {
+		// keep the paging info of the first page and append the rest
+		if result == nil {
+			result = pager.Page()
+			continue
+		}
+
+		result.Restaurants = append(result.Restaurants, pager.Page().Restaurants...)
+	}
+
+	if err := pager.Err(); err != nil {
+		if result != nil {
+			return nil, fmt.Errorf("failed to get next page: %w", err)
+		}
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// SearchPage fetches the single page of results selected by
+// query.PageIndex.
+func (c *Client) SearchPage(ctx context.Context, query search.Query) (*search.Result, error) {
+	resp, err := c.SearchPageRaw(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Result, nil
+}
+
+// SearchPageRaw fetches the single page of results selected by
+// query.PageIndex, returning the exact upstream payload and headers along
+// with the decoded page. Pages served from the cache have no headers, so
+// pass a context from BypassCache when they are needed.
+func (c *Client) SearchPageRaw(ctx context.Context, query search.Query) (*SearchPageResponse, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
 	// marshal query to json
 	queryJSON, err := json.Marshal(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal query: %w", err)
 	}
 
-	// create request
-	req, err := http.NewRequest("POST", BASE_URL+"/restaurant/v3/restaurant", bytes.NewBuffer(queryJSON))
+	// execute request
+	req := &Request{Endpoint: EndpointSearch, Method: "POST", Path: "/restaurant/v3/restaurant", Body: queryJSON, Query: &query}
+	resp, err := c.call(ctx, req, &search.Result{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
+	}
+
+	result, ok := resp.Result.(*search.Result)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %T", resp.Result)
+	}
+
+	return &SearchPageResponse{Result: result, Raw: newRawResponse(resp), Drift: resp.Drift}, nil
+}
+
+// GetMenu fetches the online menu for the restaurant with the given number.
+func (c *Client) GetMenu(restaurantID int) (*menu.Menu, error) {
+	return c.GetMenuContext(context.Background(), restaurantID)
+}
+
+// GetMenuContext is like GetMenu but aborts the request when ctx is done.
+func (c *Client) GetMenuContext(ctx context.Context, restaurantID int) (*menu.Menu, error) {
+	resp, err := c.GetMenuRaw(ctx, restaurantID)
+	if err != nil {
+		return nil, err
 	}
 
+	return resp.Menu, nil
+}
+
+// GetMenuRaw is like GetMenuContext but also returns the exact upstream
+// payload and headers, e.g. for archiving responses to re-parse later.
+// Menus served from the cache have no headers, so pass a context from
+// BypassCache when they are needed.
+func (c *Client) GetMenuRaw(ctx context.Context, restaurantID int) (*MenuResponse, error) {
+	return c.GetMenuWithOptions(ctx, restaurantID, menu.DefaultOptions())
+}
+
+// GetMenuWithOptions is like GetMenuRaw but requests the variant of the
+// menu selected by opts, e.g. to compare prices between channels.
+func (c *Client) GetMenuWithOptions(ctx context.Context, restaurantID int, opts menu.Options) (*MenuResponse, error) {
+	path := menuPath(restaurantID) + "?" + opts.Values().Encode()
+
 	// execute request
-	resp, err := c.httpClient.Do(req)
+	req := &Request{Endpoint: EndpointMenu, Method: "GET", Path: path, RestaurantID: restaurantID}
+	c.validators.apply(path, req)
+
+	resp, err := c.call(ctx, req, &menu.Menu{})
 	if err != nil {
-		// print the body with the error
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
-		}
+		return nil, err
+	}
+	c.validators.remember(path, resp)
 
-		return nil, fmt.Errorf("failed to execute request: %w, %s", err, string(body))
+	m, ok := resp.Result.(*menu.Menu)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type %T", resp.Result)
 	}
 
-	// check response status code
-	if resp.StatusCode != http.StatusOK {
-		// print the body with the error
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
+	return &MenuResponse{Menu: m, Raw: newRawResponse(resp), Drift: resp.Drift}, nil
+}
+
+// menuPath returns the path of the online menu of a restaurant.
+func menuPath(restaurantID int) string {
+	return fmt.Sprintf("/menuinnovation/v1/restaurants/%d/onlinemenu", restaurantID)
+}
+
+// fetch returns the response for req from the cache if possible, and
+// otherwise sends it with do. The response is cached by call once it has
+// been decoded.
+func (c *Client) fetch(ctx context.Context, req *Request) (*Response, error) {
+	ttl := c.cacheTTL(req.Endpoint)
+	if ttl <= 0 {
+		return c.do(ctx, req)
+	}
+
+	key := cacheKey(req.Endpoint, req.Method, req.Path, req.Body)
+	if !cacheBypassed(ctx) {
+		if data, ok := c.cache.Get(key); ok {
+			c.debug(ctx, "chipotle: serving response from cache", "endpoint", req.Endpoint, "path", req.Path)
+			return &Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: data, Cached: true}, nil
 		}
+	}
+
+	return c.do(ctx, req)
+}
 
-		return nil, fmt.Errorf("unexpected status code: %d, %s", resp.StatusCode, string(body))
+// refreshCredentials refreshes the credential provider, if it supports it,
+// and reports whether it was refreshed.
+func (c *Client) refreshCredentials(ctx context.Context) bool {
+	refresher, ok := c.credentials.(CredentialRefresher)
+	if !ok {
+		return false
 	}
 
-	// decode response
-	var result search.Result
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := refresher.Refresh(ctx); err != nil {
+		c.debug(ctx, "chipotle: failed to refresh credentials", "error", err)
+		return false
 	}
 
-	// handle pagination
-	if result.PagingInfo.CurrentPage < result.PagingInfo.TotalPages {
-		// create a new query with the next page
-		// and recursively call Search
-		// append the results to the current result
-		// return the final result
-		query.PageIndex = result.PagingInfo.CurrentPage + 1
-		nextResult, err := c.Search(query)
+	c.debug(ctx, "chipotle: refreshed credentials after 401")
+
+	return true
+}
+
+// do sends req, retrying it according to the client's retry policy and
+// pacing every attempt with the endpoint's rate limiter, and returns the
+// first response with a 200 status. Attempts fail fast while the
+// endpoint's circuit breaker is open.
+func (c *Client) do(ctx context.Context, r *Request) (*Response, error) {
+	policy := c.retryPolicy
+	limiter := c.RateLimiter(r.Endpoint)
+	breaker := c.breakers[r.Endpoint]
+	refreshed := false
+
+	for attempt := 1; ; attempt++ {
+		ticket, err := breaker.allow()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get next page: %w", err)
+			return nil, fmt.Errorf("%w: %s", err, r.Endpoint)
 		}
 
-		result.Restaurants = append(result.Restaurants, nextResult.Restaurants...)
-	}
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				breaker.release(ticket)
+				return nil, fmt.Errorf("failed to wait for rate limiter: %w", err)
+			}
+		}
+
+		req, err := c.newRequest(ctx, r)
+		if err != nil {
+			breaker.release(ticket)
+			return nil, err
+		}
 
-	return &result, nil
+		resp, respBody, err := c.send(ctx, r, req, attempt)
+		breaker.record(ctx, ticket, resp, err)
+
+		var delay time.Duration
+		var retryStatus int
+
+		switch {
+		case err != nil:
+			if attempt >= policy.attempts() || ctx.Err() != nil || !policy.retryableError(err) {
+				return nil, err
+			}
+
+		case resp.StatusCode == http.StatusOK:
+			return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody, Attempts: attempt}, nil
+
+		case resp.StatusCode == http.StatusNotModified:
+			return nil, newNotModifiedError(r, resp)
+
+		default:
+			// a rejected key gets one immediate retry after refreshing it
+			if resp.StatusCode == http.StatusUnauthorized && !refreshed && c.refreshCredentials(ctx) {
+				refreshed = true
+				continue
+			}
+
+			if attempt >= policy.attempts() || !policy.retryableStatus(resp.StatusCode) {
+				return nil, newAPIError(r.Endpoint, resp, respBody)
+			}
+
+			delay = policy.retryAfter(resp)
+			retryStatus = resp.StatusCode
+		}
+
+		c.tel.recordRetry(ctx, r.Endpoint, retryStatus)
+
+		if delay == 0 {
+			delay = policy.backoff(attempt)
+		}
+
+		c.debug(ctx, "chipotle: retrying request", "endpoint", r.Endpoint, "path", r.Path, "attempt", attempt, "delay", delay)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, fmt.Errorf("failed to execute request: %w", err)
+		}
+	}
 }
 
-func (c *Client) GetMenu(restaurantID int) (*menu.Menu, error) {
-	baseURL := "https://services.chipotle.com/menuinnovation/v1/restaurants/%d/onlinemenu?channelId=web&includeUnavailableItems=true"
+// newRequest builds the HTTP request for one attempt at r, with a fresh
+// body and the current API key.
+func (c *Client) newRequest(ctx context.Context, r *Request) (*http.Request, error) {
+	var reqBody io.Reader
+	if r.Body != nil {
+		reqBody = bytes.NewReader(r.Body)
+	}
 
-	// create request
-	req, err := http.NewRequest("GET", fmt.Sprintf(baseURL, restaurantID), nil)
+	req, err := http.NewRequestWithContext(ctx, r.Method, c.baseURL+r.Path, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// execute request
-	resp, err := c.httpClient.Do(req)
+	apiKey, err := c.credentials.APIKey(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, fmt.Errorf("failed to get API key: %w", err)
 	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", apiKey)
 
-	// check response status code
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	for key, values := range r.Header {
+		req.Header[key] = values
 	}
 
-	// decode response
-	var menu menu.Menu
-	if err := json.NewDecoder(resp.Body).Decode(&menu); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	return req, nil
+}
+
+// send executes a single attempt at r and reads the response body in full,
+// releasing the connection.
+func (c *Client) send(ctx context.Context, r *Request, req *http.Request, attempt int) (*http.Response, []byte, error) {
+	c.debug(ctx, "chipotle: sending request", "endpoint", r.Endpoint, "method", r.Method, "path", r.Path, "attempt", attempt)
+	start := time.Now()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.debug(ctx, "chipotle: request failed", "endpoint", r.Endpoint, "path", r.Path, "attempt", attempt, "duration", time.Since(start), "error", err)
+		return nil, nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.debug(ctx, "chipotle: failed to read response body", "endpoint", r.Endpoint, "path", r.Path, "attempt", attempt, "duration", time.Since(start), "error", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	return &menu, nil
+	c.debug(ctx, "chipotle: received response", "endpoint", r.Endpoint, "path", r.Path, "attempt", attempt, "duration", time.Since(start), "status", resp.StatusCode)
+
+	return resp, body, nil
 }
@@ -0,0 +1,237 @@
+// Package chipotletest provides helpers for testing code that uses the
+// chipotle client without talking to the live API.
+package chipotletest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNoFixture is returned in replay mode when no fixture matches a request.
+var ErrNoFixture = errors.New("chipotletest: no fixture for request")
+
+// Mode selects whether a Recorder talks to the real API or serves fixtures.
+type Mode int
+
+const (
+	// ModeReplay serves every request from fixtures and fails requests
+	// that have none.
+	ModeReplay Mode = iota
+	// ModeRecord sends every request upstream and saves the exchange,
+	// overwriting any existing fixture.
+	ModeRecord
+	// ModeReplayOrRecord serves requests from fixtures when present and
+	// records the ones that are missing.
+	ModeReplayOrRecord
+)
+
+// scrubbedHeaders are never written to fixtures in the clear.
+var scrubbedHeaders = []string{
+	"Ocp-Apim-Subscription-Key",
+	"Authorization",
+}
+
+// Interaction is one recorded request and its response, as stored in a
+// fixture file.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the request half of an Interaction.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the response half of an Interaction.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper that records exchanges with the API to
+// fixture files and replays them later. Pass it to chipotle.WithTransport.
+// Fixtures are matched on method, path, query and body, so they replay
+// against any base URL.
+type Recorder struct {
+	dir       string
+	mode      Mode
+	transport http.RoundTripper
+
+	// mu serializes fixture writes
+	mu sync.Mutex
+}
+
+// NewRecorder returns a Recorder storing fixtures in dir. In the recording
+// modes requests are sent through transport, or http.DefaultTransport if
+// it is nil.
+func NewRecorder(dir string, mode Mode, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &Recorder{
+		dir:       dir,
+		mode:      mode,
+		transport: transport,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Read the body so it can be hashed and still be sent upstream
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	path := r.fixturePath(req, body)
+
+	if r.mode != ModeRecord {
+		interaction, err := readFixture(path)
+		switch {
+		case err == nil:
+			return interaction.Response.toHTTP(req), nil
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, err
+		case r.mode == ModeReplay:
+			return nil, fmt.Errorf("%w: %s %s", ErrNoFixture, req.Method, req.URL.RequestURI())
+		}
+	}
+
+	return r.record(req, body, path)
+}
+
+// record sends req upstream and saves the exchange to path.
+func (r *Recorder) record(req *http.Request, body []byte, path string) (*http.Response, error) {
+	upstream := req.Clone(req.Context())
+	upstream.Body = io.NopCloser(bytes.NewReader(body))
+	upstream.ContentLength = int64(len(body))
+
+	resp, err := r.transport.RoundTrip(upstream)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Header: scrub(req.Header),
+			Body:   string(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	}
+
+	if err := r.writeFixture(path, interaction); err != nil {
+		return nil, err
+	}
+
+	return interaction.Response.toHTTP(req), nil
+}
+
+// fixturePath names the fixture for a request after its method and path,
+// plus a hash of everything used to match it.
+func (r *Recorder) fixturePath(req *http.Request, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", req.Method, req.URL.RequestURI())
+	h.Write(body)
+
+	name := strings.Trim(strings.ReplaceAll(req.URL.Path, "/", "_"), "_")
+
+	return filepath.Join(r.dir, fmt.Sprintf("%s_%s_%s.json", req.Method, name, hex.EncodeToString(h.Sum(nil))[:16]))
+}
+
+func (r *Recorder) writeFixture(path string, interaction Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal fixture: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+
+	return nil
+}
+
+func readFixture(path string) (*Interaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var interaction Interaction
+	if err := json.Unmarshal(data, &interaction); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture %s: %w", path, err)
+	}
+
+	return &interaction, nil
+}
+
+// scrub copies header with credentials redacted.
+func scrub(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range scrubbedHeaders {
+		if header.Get(key) != "" {
+			header.Set(key, "REDACTED")
+		}
+	}
+
+	return header
+}
+
+func (r RecordedResponse) toHTTP(req *http.Request) *http.Response {
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,113 @@
+package chipotletest_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+	"github.com/kylegrantlucas/chipotle-go/menu"
+)
+
+const apiKey = "secret-subscription-key"
+
+// newServer starts a fake API server with a menu for restaurant 1 that
+// requires apiKey.
+func newServer(t *testing.T) *chipotletest.Server {
+	t.Helper()
+
+	s := chipotletest.NewServer()
+	t.Cleanup(s.Close)
+	s.RequireAPIKey(apiKey)
+	s.SetMenu(&menu.Menu{RestaurantID: 1, Sides: []menu.Side{{ItemName: "Chips", IsItemAvailable: true}}})
+
+	return s
+}
+
+func TestRecorder(t *testing.T) {
+	tests := []struct {
+		name string
+		// prerecord records the menu request before the test
+		prerecord    bool
+		mode         chipotletest.Mode
+		wantErr      error
+		wantRequests int
+	}{
+		{name: "record", mode: chipotletest.ModeRecord, wantRequests: 1},
+		{name: "record overwrites", mode: chipotletest.ModeRecord, prerecord: true, wantRequests: 2},
+		{name: "replay", mode: chipotletest.ModeReplay, prerecord: true, wantRequests: 1},
+		{name: "replay missing", mode: chipotletest.ModeReplay, wantErr: chipotletest.ErrNoFixture},
+		{name: "replay or record missing", mode: chipotletest.ModeReplayOrRecord, wantRequests: 1},
+		{name: "replay or record present", mode: chipotletest.ModeReplayOrRecord, prerecord: true, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t)
+			dir := t.TempDir()
+
+			if tt.prerecord {
+				if _, err := getMenu(s.URL, dir, chipotletest.ModeRecord); err != nil {
+					t.Fatalf("recording: %v", err)
+				}
+			}
+
+			m, err := getMenu(s.URL, dir, tt.mode)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetMenuContext() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (len(m.Sides) != 1 || m.Sides[0].ItemName != "Chips") {
+				t.Errorf("GetMenuContext() = %+v, want the server's menu", m)
+			}
+
+			if got := s.Requests(chipotle.EndpointMenu); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRecorderScrubsAPIKey(t *testing.T) {
+	s := newServer(t)
+	dir := t.TempDir()
+
+	if _, err := getMenu(s.URL, dir, chipotletest.ModeRecord); err != nil {
+		t.Fatalf("recording: %v", err)
+	}
+
+	fixtures, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(fixtures) != 1 {
+		t.Fatalf("fixtures = %v, %v, want one", fixtures, err)
+	}
+	data, err := os.ReadFile(fixtures[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), apiKey) {
+		t.Errorf("fixture contains the API key:\n%s", data)
+	}
+	if !strings.Contains(string(data), "REDACTED") {
+		t.Errorf("fixture doesn't mark the API key as redacted:\n%s", data)
+	}
+
+	// the fixture replays without the server, whatever the key
+	s.Close()
+	c := chipotle.NewClient("another-key", chipotle.WithBaseURL("http://localhost:0"), chipotle.WithTransport(chipotletest.NewRecorder(dir, chipotletest.ModeReplay, nil)))
+	if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+		t.Errorf("replaying: %v", err)
+	}
+}
+
+// getMenu fetches menu 1 from baseURL through a recorder in mode.
+func getMenu(baseURL, dir string, mode chipotletest.Mode) (*menu.Menu, error) {
+	c := chipotle.NewClient(apiKey,
+		chipotle.WithBaseURL(baseURL),
+		chipotle.WithTransport(chipotletest.NewRecorder(dir, mode, nil)),
+	)
+
+	return c.GetMenuContext(context.Background(), 1)
+}
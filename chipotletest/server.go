@@ -0,0 +1,402 @@
+package chipotletest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/menu"
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+// DefaultPageSize is the page size the fake server uses when a search
+// doesn't set one.
+const DefaultPageSize = 25
+
+// Server is an in-process fake of the Chipotle API serving a programmable
+// dataset. Point a client at it with chipotle.WithBaseURL(server.URL).
+//
+// Searches read the radius and report distances in search.RadiusUnit and
+// search.ResultDistanceUnit, so the server agrees with the client by
+// construction and can't confirm those units are the API's.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	restaurants []restaurant.Restaurant
+	menus       map[int]*menu.Menu
+	apiKey      string
+	maxPageSize int
+	latency     time.Duration
+	faults      []*Fault
+	requests    map[chipotle.Endpoint]int
+}
+
+// Fault describes a failure the server injects into matching requests.
+type Fault struct {
+	// Endpoint restricts the fault to one endpoint. Empty matches both.
+	Endpoint chipotle.Endpoint
+	// Times is the number of requests the fault affects. Zero means every
+	// matching request.
+	Times int
+
+	// Latency delays the response.
+	Latency time.Duration
+	// StatusCode, if set, replaces the response with an error response.
+	StatusCode int
+	// RetryAfter is sent as a Retry-After header with StatusCode.
+	RetryAfter time.Duration
+	// Truncate cuts the successful response body in half, producing
+	// invalid JSON.
+	Truncate bool
+}
+
+// NewServer starts a fake API server with an empty dataset. Callers must
+// call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		menus:    map[int]*menu.Menu{},
+		requests: map[chipotle.Endpoint]int{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /restaurant/v3/restaurant", s.handleSearch)
+	mux.HandleFunc("GET /menuinnovation/v1/restaurants/{id}/onlinemenu", s.handleMenu)
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// AddRestaurants adds restaurants to the dataset. A restaurant's position
+// is taken from its first address.
+func (s *Server) AddRestaurants(restaurants ...restaurant.Restaurant) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.restaurants = append(s.restaurants, restaurants...)
+}
+
+// SetMenu serves m as the menu of the restaurant m.RestaurantID.
+// Restaurants without a menu answer with a 404. Requests with
+// includeUnavailableItems=false only get the available items. Menus carry
+// an ETag and answer If-None-Match requests for an unchanged menu with a
+// 304.
+func (s *Server) SetMenu(m *menu.Menu) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.menus[m.RestaurantID] = m
+}
+
+// RequireAPIKey makes the server reject requests without this
+// subscription key with a 401.
+func (s *Server) RequireAPIKey(apiKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.apiKey = apiKey
+}
+
+// SetMaxPageSize caps the page size the server honors, like an API that
+// limits how many results one page may hold. Zero removes the cap.
+func (s *Server) SetMaxPageSize(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxPageSize = n
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = d
+}
+
+// InjectFault queues a fault. Faults are applied in the order they were
+// injected; the first one matching a request is used.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// Requests returns how many requests endpoint has received.
+func (s *Server) Requests(endpoint chipotle.Endpoint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[endpoint]
+}
+
+// begin records a request to endpoint and applies latency, authentication
+// and faults. It returns the fault to apply to the response body, or false
+// if a response has already been written.
+func (s *Server) begin(w http.ResponseWriter, r *http.Request, endpoint chipotle.Endpoint) (Fault, bool) {
+	s.mu.Lock()
+	s.requests[endpoint]++
+	latency, apiKey := s.latency, s.apiKey
+
+	var fault Fault
+	for i, f := range s.faults {
+		if f.Endpoint != "" && f.Endpoint != endpoint {
+			continue
+		}
+
+		fault = *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		break
+	}
+	s.mu.Unlock()
+
+	if d := latency + fault.Latency; d > 0 {
+		select {
+		case <-time.After(d):
+		case <-r.Context().Done():
+			return fault, false
+		}
+	}
+
+	if apiKey != "" && r.Header.Get("Ocp-Apim-Subscription-Key") != apiKey {
+		writeError(w, http.StatusUnauthorized, "Access denied due to invalid subscription key.")
+		return fault, false
+	}
+
+	if fault.StatusCode != 0 {
+		if fault.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(fault.RetryAfter.Seconds()))))
+		}
+		writeError(w, fault.StatusCode, http.StatusText(fault.StatusCode))
+		return fault, false
+	}
+
+	return fault, true
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	fault, ok := s.begin(w, r, chipotle.EndpointSearch)
+	if !ok {
+		return
+	}
+
+	var query search.Query
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	matches := s.search(query)
+	maxPageSize := s.maxPageSize
+	s.mu.Unlock()
+
+	// Paginate. Pages are numbered from one and a page index of zero
+	// selects the first page.
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if maxPageSize > 0 {
+		pageSize = min(pageSize, maxPageSize)
+	}
+
+	page := max(query.PageIndex, 1)
+	start := min((page-1)*pageSize, len(matches))
+	end := min(start+pageSize, len(matches))
+
+	writeJSON(w, search.Result{
+		Restaurants: matches[start:end],
+		PagingInfo: search.PagingInfo{
+			CurrentPage:  page,
+			TotalPages:   (len(matches) + pageSize - 1) / pageSize,
+			ItemsPerPage: pageSize,
+			TotalItems:   len(matches),
+		},
+	}, fault.Truncate)
+}
+
+// search returns the restaurants matching query, trimmed to the requested
+// embeds. s.mu must be held.
+func (s *Server) search(query search.Query) []restaurant.Restaurant {
+	matches := []restaurant.Restaurant{}
+	for _, r := range s.restaurants {
+		if len(query.RestaurantStatuses) > 0 && !contains(query.RestaurantStatuses, r.RestaurantStatus) {
+			continue
+		}
+
+		if len(r.Addresses) > 0 && (query.Latitude != 0 || query.Longitude != 0) {
+			d := search.DistanceBetween(query.Latitude, query.Longitude, r.Addresses[0].Latitude, r.Addresses[0].Longitude)
+			if query.Radius > 0 && d > query.RadiusDistance() {
+				continue
+			}
+
+			r.Distance = float64(d / search.ResultDistanceUnit)
+		}
+
+		matches = append(matches, embed(r, query.Embeds))
+	}
+
+	if query.OrderBy == string(search.OrderFieldDistance) {
+		sort.SliceStable(matches, func(i, j int) bool {
+			if query.OrderByDescending {
+				return matches[i].Distance > matches[j].Distance
+			}
+			return matches[i].Distance < matches[j].Distance
+		})
+	}
+
+	return matches
+}
+
+// embed clears the sections of r that weren't asked for in embeds.
+func embed(r restaurant.Restaurant, embeds search.Embeds) restaurant.Restaurant {
+	addresses := []restaurant.Address{}
+	for _, a := range r.Addresses {
+		if contains(embeds.AddressTypes, a.AddressType) {
+			addresses = append(addresses, a)
+		}
+	}
+	r.Addresses = addresses
+
+	if !embeds.RealHours {
+		r.RealHours = nil
+	}
+	if !embeds.Directions {
+		r.Directions = restaurant.Directions{}
+	}
+	if !embeds.Catering {
+		r.Catering = restaurant.Catering{}
+	}
+	if !embeds.OnlineOrdering {
+		r.OnlineOrdering = restaurant.OnlineOrdering{}
+	}
+	if !embeds.Timezone {
+		r.Timezone = restaurant.Timezone{}
+	}
+	if !embeds.Marketing {
+		r.Marketing = restaurant.Marketing{}
+	}
+	if !embeds.Chipotlane {
+		r.Chipotlane = restaurant.Chipotlane{}
+	}
+	if !embeds.Sustainability {
+		r.Sustainability = restaurant.Sustainability{}
+	}
+	if !embeds.Experience {
+		r.Experience = restaurant.Experience{}
+	}
+
+	return r
+}
+
+func (s *Server) handleMenu(w http.ResponseWriter, r *http.Request) {
+	fault, ok := s.begin(w, r, chipotle.EndpointMenu)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid restaurant id")
+		return
+	}
+
+	s.mu.Lock()
+	m, found := s.menus[id]
+	s.mu.Unlock()
+
+	if !found {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no online menu for restaurant %d", id))
+		return
+	}
+
+	if r.URL.Query().Get("includeUnavailableItems") == "false" {
+		m = availableItems(m)
+	}
+
+	// tag the menu with a hash of its contents so clients can poll it with
+	// conditional requests
+	if data, err := json.Marshal(m); err == nil {
+		sum := sha256.Sum256(data)
+		etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+		w.Header().Set("ETag", etag)
+
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	writeJSON(w, m, fault.Truncate)
+}
+
+// availableItems returns a copy of m without the items that aren't
+// available.
+func availableItems(m *menu.Menu) *menu.Menu {
+	out := *m
+	out.Entrees = filter(m.Entrees, func(e menu.Entree) bool { return e.IsItemAvailable })
+	out.Sides = filter(m.Sides, func(s menu.Side) bool { return s.IsItemAvailable })
+	out.Drinks = filter(m.Drinks, func(d menu.Drink) bool { return d.IsItemAvailable })
+	out.NonFoodItems = filter(m.NonFoodItems, func(n menu.NonFoodItem) bool { return n.IsItemAvailable })
+
+	return &out
+}
+
+func filter[T any](items []T, keep func(T) bool) []T {
+	var kept []T
+	for _, item := range items {
+		if keep(item) {
+			kept = append(kept, item)
+		}
+	}
+
+	return kept
+}
+
+func writeJSON(w http.ResponseWriter, v any, truncate bool) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if truncate {
+		data = data[:len(data)/2]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(chipotle.ErrorBody{StatusCode: statusCode, Message: message})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"sync"
 
 	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
 	"github.com/kylegrantlucas/chipotle-go/menu"
 	"github.com/kylegrantlucas/chipotle-go/restaurant"
 	"github.com/kylegrantlucas/chipotle-go/search"
@@ -16,154 +20,241 @@ import (
 )
 
 func main() {
-	client := chipotle.NewClient("INSERT_YOUR_API_KEY_HERE")
-
-	query := search.Query{
-		Latitude:           38.495693700000004,
-		Longitude:          -121.19452040000002,
-		Radius:             9046700,
-		RestaurantStatuses: []string{"OPEN", "LAB"},
-		ConceptIds:         []string{"CMG"},
-		OrderBy:            "distance",
-		OrderByDescending:  false,
-		PageSize:           4000,
-		PageIndex:          0,
-		Embeds: search.Embeds{
-			AddressTypes:   []string{"MAIN"},
-			RealHours:      true,
-			Directions:     true,
-			Catering:       true,
-			OnlineOrdering: true,
-			Timezone:       true,
-			Marketing:      true,
-			Chipotlane:     true,
-			Sustainability: true,
-			Experience:     true,
-		},
-	}
-
-	fmt.Println("Searching for restaurants...")
-	result, err := client.Search(query)
+	fixtures := flag.String("fixtures", "", "replay API responses from this fixture directory instead of calling the API")
+	record := flag.Bool("record", false, "call the API and record its responses to the -fixtures directory")
+	logFormat := flag.String("log-format", "text", "log output format: text or json")
+	logLevel := flag.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	storeRaw := flag.Bool("store-raw", false, "store the original menu payloads in the raw_menus table")
+	sweep := flag.Bool("sweep", false, "search the United States and Canada tile by tile instead of with one nationwide radius")
+	apiKeyFile := flag.String("api-key-file", "", "read the subscription key from this file, re-reading it when it changes (default: $CHIPOTLE_API_KEY)")
+	flag.Parse()
+
+	logger, err := newLogger(*logFormat, *logLevel)
 	if err != nil {
-		log.Fatal(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// read the subscription key from the file if given, otherwise the environment
+	credentials := chipotle.ChainCredentials{chipotle.EnvCredentials("CHIPOTLE_API_KEY")}
+	if *apiKeyFile != "" {
+		credentials = append(chipotle.ChainCredentials{chipotle.NewFileCredentials(*apiKeyFile)}, credentials...)
+	}
+
+	// retry throttled and failed requests so transient errors don't drop menus,
+	// pace the menu workers so they don't get throttled in the first place,
+	// and stop hammering the API while it is down
+	opts := []chipotle.Option{
+		chipotle.WithRetryPolicy(chipotle.DefaultRetryPolicy()),
+		chipotle.WithRateLimit(20, 40),
+		chipotle.WithCircuitBreaker(chipotle.DefaultCircuitBreakerSettings()),
+		chipotle.WithLogger(logger),
+		chipotle.WithCredentials(credentials),
+		chipotle.WithSchemaDriftDetection(),
+	}
+
+	// serve the API from recorded fixtures so the export can run offline
+	if *fixtures != "" {
+		mode := chipotletest.ModeReplay
+		if *record {
+			mode = chipotletest.ModeRecord
+		}
+
+		opts = append(opts, chipotle.WithTransport(chipotletest.NewRecorder(*fixtures, mode, nil)))
+
+		// replaying needs no real key
+		if !*record {
+			opts = append(opts, chipotle.WithCredentials(chipotle.StaticCredentials("replay")))
+		}
+	}
+
+	client := chipotle.NewClient("", opts...)
+
+	query := exportQuery()
+
+	logger.Info("searching for restaurants", "stage", "search", "sweep", *sweep)
+	var restaurants []restaurant.Restaurant
+	if *sweep {
+		// tiles with more than one page of restaurants get split into smaller
+		// ones; cover Canada too, which the nationwide radius reaches
+		sweepQuery := query.PerPage(1000)
+		regions := append(append([]search.BoundingBox{}, search.UnitedStates...), search.Canada)
+		result, err := search.Sweep(context.Background(), client, sweepQuery, search.SweepOptions{Regions: regions})
+		if err != nil {
+			fatal(logger, "failed to sweep for restaurants", "stage", "search", "error", err)
+		}
+
+		for _, t := range result.Saturated {
+			logger.Warn("tile still spans several pages at maximum depth, restaurants may be missing", "stage", "search", "tile", t.String(), "count", t.Count)
+		}
+		logger.Info("swept tiles", "stage", "search", "tiles", result.Tiles, "subdivided", len(result.Subdivided))
+
+		restaurants = result.Restaurants
+	} else {
+		result, err := client.Search(query)
+		if err != nil {
+			fatal(logger, "failed to search for restaurants", "stage", "search", "error", err)
+		}
+
+		restaurants = result.Restaurants
 	}
 
 	// some stats
-	fmt.Printf("Total restaurants: %d\n", len(result.Restaurants))
+	logger.Info("found restaurants", "stage", "search", "count", len(restaurants))
 
 	// drop the old database, we don't care if it doesn't exist, so ignore that class of error
 	err = os.Remove("./chipotle.db")
 	if err != nil && !os.IsNotExist(err) {
-		log.Fatal(err)
+		fatal(logger, "failed to remove old database", "stage", "database", "error", err)
 	}
 
 	// Open the database connection
-	fmt.Println("Opening database connection...")
+	logger.Info("opening database connection", "stage", "database")
 	db, err := sql.Open("sqlite3", "./chipotle.db")
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "failed to open database", "stage", "database", "error", err)
 	}
 	defer db.Close()
 
 	// Set some DB pragmas to speed this up
-	fmt.Println("Setting database pragmas to speed up insertion...")
+	logger.Info("setting database pragmas to speed up insertion", "stage", "database")
 	_, err = db.Exec("PRAGMA synchronous = OFF")
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "failed to set database pragma", "stage", "database", "error", err)
 	}
 
 	_, err = db.Exec("PRAGMA journal_mode = OFF")
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "failed to set database pragma", "stage", "database", "error", err)
 	}
 
 	// Create tables
-	fmt.Println("Creating tables...")
+	logger.Info("creating tables", "stage", "database")
 	createTables(db)
 
 	// Configurable running thread limit for fetching menus
 	fetchThreadLimit := 75
 
-	// Create channels for restaurant processing
-	restaurantChan := make(chan restaurant.Restaurant)
+	// Insert the restaurants and index them by number for logging
+	logger.Info("inserting restaurants", "stage", "restaurants")
+	restaurantIDs := make([]int, 0, len(restaurants))
+	restaurantNames := make(map[int]string, len(restaurants))
+	for _, r := range restaurants {
+		err := insertRestaurant(db, r)
+		if err != nil {
+			fatal(logger, "failed to insert restaurant", "stage", "restaurants",
+				"restaurant_number", r.RestaurantNumber, "restaurant_name", r.RestaurantName, "error", err)
+		}
 
-	menus := []*menu.Menu{}
+		restaurantIDs = append(restaurantIDs, r.RestaurantNumber)
+		restaurantNames[r.RestaurantNumber] = r.RestaurantName
+	}
 
-	// Wait groups for the two stages
-	var fetchWg sync.WaitGroup
+	// log the start
+	logger.Info("fetching menus", "stage", "menus", "count", len(restaurantIDs))
 
-	// Mutex for database operations
-	var menuMutex sync.Mutex
-	var dbMutex sync.Mutex
+	menus := []*menu.Menu{}
+	for res := range client.GetMenus(context.Background(), restaurantIDs, chipotle.GetMenusOptions{Concurrency: fetchThreadLimit}) {
+		if errors.Is(res.Err, chipotle.ErrNotFound) {
+			logger.Warn("restaurant has no online menu", "stage", "menus",
+				"restaurant_number", res.RestaurantID, "restaurant_name", restaurantNames[res.RestaurantID])
+			continue
+		} else if res.Err != nil {
+			logger.Error("failed to get menu", "stage", "menus",
+				"restaurant_number", res.RestaurantID, "restaurant_name", restaurantNames[res.RestaurantID], "error", res.Err)
+			continue
+		}
 
-	// log the start
-	fmt.Println("Fetching menus and inserting restaurants...")
-
-	// Start goroutines for fetching menus
-	for i := 0; i < fetchThreadLimit; i++ {
-		fetchWg.Add(1)
-		go func() {
-			defer fetchWg.Done()
-			for r := range restaurantChan {
-				// insert the restaurant into the database
-				dbMutex.Lock()
-				err := insertRestaurant(db, r)
-				if err != nil {
-					log.Fatal(err)
-				}
-				dbMutex.Unlock()
-
-				m, err := client.GetMenu(r.RestaurantNumber)
-				if err != nil {
-					log.Printf("Error getting menu for restaurant %s: %v\n", r.RestaurantName, err)
-					continue
-				}
-
-				menuMutex.Lock()
-				menus = append(menus, m)
-				menuMutex.Unlock()
+		if *storeRaw {
+			err := insertRawMenu(db, res.RestaurantID, res.Raw)
+			if err != nil {
+				fatal(logger, "failed to insert raw menu", "stage", "menus",
+					"restaurant_number", res.RestaurantID, "restaurant_name", restaurantNames[res.RestaurantID], "error", err)
 			}
-		}()
-	}
+		}
 
-	// Send restaurants to the fetching pool
-	for _, restaurant := range result.Restaurants {
-		restaurantChan <- restaurant
+		menus = append(menus, res.Menu)
 	}
 
-	// Close the restaurant channel to signal fetch goroutines to finish
-	close(restaurantChan)
-	fetchWg.Wait()
+	// report how much the rate limiter slowed us down
+	stats := client.RateLimiter(chipotle.EndpointMenu).Stats()
+	logger.Info("fetched menus", "stage", "menus", "count", len(menus),
+		"requests", stats.Requests, "delayed", stats.Delayed, "total_wait", stats.TotalWait)
 
 	oi := optimizeItems(menus)
 
 	// Insert optimized items into the database
-	fmt.Println("Inserting optimized items into the database...")
+	logger.Info("inserting optimized items into the database", "stage", "items")
 	err = insertOptimizedItems(db, oi)
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "failed to insert optimized items", "stage", "items", "error", err)
 	}
 
 	// Insert menus into the database
-	fmt.Println("Inserting menus into the database...")
+	logger.Info("inserting menus into the database", "stage", "items")
 	for _, m := range menus {
 		err = insertMenu(db, m, oi)
 		if err != nil {
-			log.Fatal(err)
+			fatal(logger, "failed to insert menu", "stage", "items",
+				"restaurant_number", m.RestaurantID, "restaurant_name", restaurantNames[m.RestaurantID], "error", err)
 		}
 	}
 
 	// reset the pragmas
-	fmt.Println("Resetting database PRAGMAs...")
+	logger.Info("resetting database pragmas", "stage", "database")
 	_, err = db.Exec("PRAGMA synchronous = FULL")
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "failed to reset database pragma", "stage", "database", "error", err)
 	}
 
 	_, err = db.Exec("PRAGMA journal_mode = DELETE")
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "failed to reset database pragma", "stage", "database", "error", err)
+	}
+
+	// summarize fields the models are missing so they can be kept in sync
+	for _, d := range client.SchemaDrift() {
+		logger.Warn("schema drift", "stage", "done", "endpoint", d.Endpoint, "kind", d.Kind, "path", d.Path, "sample", d.Sample, "count", d.Count)
 	}
+
+	logger.Info("export complete", "stage", "done")
+}
+
+// exportQuery is the search covering every restaurant in one nationwide
+// radius.
+func exportQuery() search.Query {
+	return search.NewQuery().
+		Near(38.495693700000004, -121.19452040000002).
+		WithinMeters(9046700).
+		Statuses(search.StatusOpen, search.StatusLab).
+		OrderByDistance().
+		PerPage(search.MaxPageSize).
+		EmbedAll()
+}
+
+// newLogger builds the structured logger selected by the -log-format and
+// -log-level flags.
+func newLogger(format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+}
+
+// fatal logs msg at error level and exits.
+func fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Error(msg, args...)
+	os.Exit(1)
 }
 
 func createTables(db *sql.DB) error {
@@ -423,11 +514,22 @@ func createTables(db *sql.DB) error {
 		FOREIGN KEY(restaurant_id) REFERENCES restaurants(id)
 	);`
 
+	// Create RawMenus table, holding the original payloads for re-parsing
+	createRawMenusTable := `
+	CREATE TABLE IF NOT EXISTS raw_menus (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		restaurant_id INTEGER,
+		headers TEXT,
+		body TEXT,
+		FOREIGN KEY(restaurant_id) REFERENCES restaurants(id)
+	);`
+
 	// Execute the table creation queries
 	queries := []string{
 		createMenuTable, createItemTypesTable, createItemCategoriesTable, createItemNamesTable, createPrimaryFillingNamesTable,
 		createItemsTable, createEntreeTable, createEntreeContentGroupsTable, createContentGroupsTable, createContentsTable,
 		createDrinkTable, createNonFoodItemTable, createSideTable, createRestaurantTable, createAddressTable, createRealHoursTable,
+		createRawMenusTable,
 	}
 
 	for _, query := range queries {
@@ -439,6 +541,20 @@ func createTables(db *sql.DB) error {
 	return nil
 }
 
+func insertRawMenu(db *sql.DB, restaurantID int, raw chipotle.RawResponse) error {
+	headers, err := json.Marshal(raw.Header)
+	if err != nil {
+		return fmt.Errorf("error encoding raw menu headers: %v", err)
+	}
+
+	_, err = db.Exec("INSERT INTO raw_menus (restaurant_id, headers, body) VALUES (?, ?, ?)", restaurantID, string(headers), string(raw.Body))
+	if err != nil {
+		return fmt.Errorf("error inserting raw menu: %v", err)
+	}
+
+	return nil
+}
+
 func insertOptimizedItems(db *sql.DB, optimizedItems *optimizedItems) error {
 	tx, err := db.Begin()
 	if err != nil {
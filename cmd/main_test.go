@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+	"github.com/kylegrantlucas/chipotle-go/menu"
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+// fixtureDir holds the API responses the export is tested against.
+const fixtureDir = "testdata/fixtures"
+
+var update = flag.Bool("update", false, "re-record the fixtures in testdata/fixtures from the fake server")
+
+// TestMain runs the crawler instead of the tests when re-executed by
+// runExport.
+func TestMain(m *testing.M) {
+	if os.Getenv("CHIPOTLE_EXPORT_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestExportReplaysFixtures(t *testing.T) {
+	if *update {
+		recordFixtures(t)
+	}
+
+	db := runExport(t, "-store-raw")
+
+	tests := []struct {
+		table string
+		want  int
+	}{
+		{table: "restaurants", want: 3},
+		{table: "addresses", want: 3},
+		// restaurant 3 has no online menu
+		{table: "menus", want: 2},
+		{table: "raw_menus", want: 2},
+		{table: "entrees", want: 2},
+		{table: "contents", want: 3},
+		{table: "sides", want: 2},
+		{table: "drinks", want: 1},
+		{table: "items", want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table, func(t *testing.T) {
+			var got int
+			if err := db.QueryRow("SELECT COUNT(*) FROM " + tt.table).Scan(&got); err != nil {
+				t.Fatalf("counting %s: %v", tt.table, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s has %d rows, want %d", tt.table, got, tt.want)
+			}
+		})
+	}
+}
+
+// runExport runs the crawler with args against the fixtures in a
+// temporary directory and returns the database it wrote.
+func runExport(t *testing.T, args ...string) *sql.DB {
+	t.Helper()
+
+	fixtures, err := filepath.Abs(fixtureDir)
+	if err != nil {
+		t.Fatalf("resolving fixture directory: %v", err)
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], append([]string{"-fixtures", fixtures, "-log-level", "warn"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "CHIPOTLE_EXPORT_MAIN=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("export failed: %v\n%s", err, out)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(dir, "chipotle.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// recordFixtures replaces the fixtures with the export's requests to a fake
+// server holding three restaurants, two of them with a menu.
+func recordFixtures(t *testing.T) {
+	t.Helper()
+
+	s := chipotletest.NewServer()
+	t.Cleanup(s.Close)
+
+	for i := 1; i <= 3; i++ {
+		s.AddRestaurants(restaurant.Restaurant{
+			RestaurantNumber: i,
+			RestaurantName:   "Folsom " + string(rune('A'-1+i)),
+			RestaurantStatus: string(search.StatusOpen),
+			Addresses: []restaurant.Address{{
+				AddressType: string(search.AddressMain),
+				Latitude:    38.67 + float64(i)*0.001,
+				Longitude:   -121.17,
+			}},
+		})
+	}
+	for i := 1; i <= 2; i++ {
+		m := &menu.Menu{
+			RestaurantID: i,
+			Entrees: []menu.Entree{{
+				ItemID:        "CMG-1001",
+				ItemName:      "Burrito",
+				ItemType:      "Entree",
+				ItemCategory:  "Burrito",
+				UnitPrice:     9.5,
+				ContentGroups: []menu.ContentGroups{{ContentGroupName: "Meat", MinQuantity: 1, MaxQuantity: 2}},
+				Contents:      []menu.Contents{{ItemID: "CMG-5001", ItemName: "Chicken", ItemType: "Meat", ContentGroupName: "Meat"}},
+			}},
+			Sides: []menu.Side{{ItemID: "CMG-2001", ItemName: "Chips", ItemType: "Side", ItemCategory: "Chips", IsItemAvailable: true}},
+		}
+		if i == 1 {
+			m.Entrees[0].Contents = append(m.Entrees[0].Contents, menu.Contents{ItemID: "CMG-5002", ItemName: "Steak", ItemType: "Meat", ContentGroupName: "Meat"})
+			m.Drinks = []menu.Drink{{ItemID: "CMG-3001", ItemName: "Lemonade", ItemType: "Drink", ItemCategory: "Drink", IsItemAvailable: true}}
+		}
+		s.SetMenu(m)
+	}
+
+	if err := os.RemoveAll(fixtureDir); err != nil {
+		t.Fatalf("removing old fixtures: %v", err)
+	}
+
+	c := chipotle.NewClient("test-key",
+		chipotle.WithBaseURL(s.URL),
+		chipotle.WithTransport(chipotletest.NewRecorder(fixtureDir, chipotletest.ModeRecord, nil)),
+	)
+
+	result, err := c.Search(exportQuery())
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	ids := make([]int, 0, len(result.Restaurants))
+	for _, r := range result.Restaurants {
+		ids = append(ids, r.RestaurantNumber)
+	}
+	for range c.GetMenus(context.Background(), ids, chipotle.GetMenusOptions{}) {
+	}
+}
@@ -0,0 +1,124 @@
+package chipotle
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrNotModified is matched by NotModifiedError through errors.Is.
+var ErrNotModified = errors.New("chipotle: not modified")
+
+// NotModifiedError is returned by the menu methods when conditional
+// requests are enabled and the menu hasn't changed since it was last
+// fetched.
+type NotModifiedError struct {
+	RestaurantID int
+	// ETag and LastModified are the validators the server confirmed.
+	ETag         string
+	LastModified string
+}
+
+// Error implements error.
+func (e *NotModifiedError) Error() string {
+	return fmt.Sprintf("chipotle: menu of restaurant %d not modified", e.RestaurantID)
+}
+
+// Is matches ErrNotModified.
+func (e *NotModifiedError) Is(target error) bool {
+	return target == ErrNotModified
+}
+
+// WithConditionalRequests makes the client remember the ETag and
+// Last-Modified headers of every menu it fetches and send them back as
+// If-None-Match and If-Modified-Since. Menus that haven't changed are then
+// reported with a NotModifiedError instead of being downloaded again.
+func WithConditionalRequests() Option {
+	return func(c *Client) {
+		c.validators = &validatorStore{byPath: map[string]validators{}}
+	}
+}
+
+// validators are the cache validators of one response.
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// validatorStore remembers validators by request path, so menus of the
+// same restaurant for different channels are tracked separately.
+type validatorStore struct {
+	mu     sync.Mutex
+	byPath map[string]validators
+}
+
+// apply adds the conditional headers for path to req.
+func (s *validatorStore) apply(path string, req *Request) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	v, ok := s.byPath[path]
+	s.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	if v.etag != "" {
+		req.Header.Set("If-None-Match", v.etag)
+	}
+	if v.lastModified != "" {
+		req.Header.Set("If-Modified-Since", v.lastModified)
+	}
+}
+
+// remember stores the validators of a fresh response to path.
+func (s *validatorStore) remember(path string, resp *Response) {
+	if s == nil || resp.Cached {
+		return
+	}
+
+	v := validators{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+	if v.etag == "" && v.lastModified == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.byPath[path] = v
+}
+
+// forget drops the validators of every path starting with prefix.
+func (s *validatorStore) forget(prefix string) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for path := range s.byPath {
+		if strings.HasPrefix(path, prefix) {
+			delete(s.byPath, path)
+		}
+	}
+}
+
+func newNotModifiedError(r *Request, resp *http.Response) *NotModifiedError {
+	return &NotModifiedError{
+		RestaurantID: r.RestaurantID,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
@@ -0,0 +1,134 @@
+package chipotle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/menu"
+)
+
+func TestConditionalRequests(t *testing.T) {
+	kiosk := menu.Options{Channel: menu.ChannelKiosk}
+
+	// step fetches menu 1 with opts, after changing the menu or
+	// invalidating it if asked to
+	type step struct {
+		opts            menu.Options
+		change          bool
+		invalidate      bool
+		wantNotModified bool
+	}
+
+	tests := []struct {
+		name         string
+		opts         []chipotle.Option
+		steps        []step
+		wantRequests int
+	}{
+		{
+			name: "unchanged",
+			opts: []chipotle.Option{chipotle.WithConditionalRequests()},
+			steps: []step{
+				{},
+				{wantNotModified: true},
+				{wantNotModified: true},
+			},
+			wantRequests: 3,
+		},
+		{
+			name: "changed",
+			opts: []chipotle.Option{chipotle.WithConditionalRequests()},
+			steps: []step{
+				{},
+				{change: true},
+				{wantNotModified: true},
+			},
+			wantRequests: 3,
+		},
+		{
+			name: "channels tracked separately",
+			opts: []chipotle.Option{chipotle.WithConditionalRequests()},
+			steps: []step{
+				{},
+				{opts: kiosk},
+				{wantNotModified: true},
+				{opts: kiosk, wantNotModified: true},
+			},
+			wantRequests: 4,
+		},
+		{
+			name: "invalidated",
+			opts: []chipotle.Option{chipotle.WithConditionalRequests()},
+			steps: []step{
+				{},
+				{opts: kiosk},
+				{invalidate: true},
+				{opts: kiosk},
+				{wantNotModified: true},
+			},
+			wantRequests: 5,
+		},
+		{
+			name: "cached",
+			opts: []chipotle.Option{chipotle.WithConditionalRequests(), chipotle.WithCache(chipotle.NewMemoryCache(0))},
+			steps: []step{
+				{},
+				// served from the cache without asking the server
+				{},
+				{invalidate: true},
+				{},
+			},
+			wantRequests: 2,
+		},
+		{
+			name: "disabled",
+			steps: []step{
+				{},
+				{},
+			},
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 1)
+			c := newClient(s, tt.opts...)
+
+			for i, step := range tt.steps {
+				if step.change {
+					s.SetMenu(&menu.Menu{RestaurantID: 1, Sides: []menu.Side{{ItemName: "Guacamole", IsItemAvailable: true}}})
+				}
+				if step.invalidate {
+					c.InvalidateMenu(1)
+				}
+
+				resp, err := c.GetMenuWithOptions(context.Background(), 1, step.opts)
+
+				if !step.wantNotModified {
+					if err != nil {
+						t.Fatalf("step %d: GetMenuWithOptions() error = %v", i, err)
+					}
+					if resp.Menu.RestaurantID != 1 {
+						t.Errorf("step %d: got the menu of %d", i, resp.Menu.RestaurantID)
+					}
+					continue
+				}
+
+				var notModified *chipotle.NotModifiedError
+				if !errors.Is(err, chipotle.ErrNotModified) || !errors.As(err, &notModified) {
+					t.Fatalf("step %d: GetMenuWithOptions() error = %v, want %v", i, err, chipotle.ErrNotModified)
+				}
+				if notModified.RestaurantID != 1 || notModified.ETag == "" {
+					t.Errorf("step %d: NotModifiedError = %+v, want restaurant 1 and its ETag", i, notModified)
+				}
+			}
+
+			if got := s.Requests(chipotle.EndpointMenu); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
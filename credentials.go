@@ -0,0 +1,180 @@
+package chipotle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNoCredentials is returned when a CredentialProvider has no key.
+var ErrNoCredentials = errors.New("chipotle: no credentials")
+
+// CredentialProvider supplies the subscription key sent in the
+// Ocp-Apim-Subscription-Key header. It is asked for the key on every
+// request, so keys can be rotated without rebuilding the client.
+type CredentialProvider interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// CredentialRefresher is implemented by providers that can reload their
+// key. When the API rejects a key with a 401, the client refreshes the
+// provider and retries the request once.
+type CredentialRefresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// WithCredentials sets the provider of the subscription key, replacing the
+// key passed to NewClient.
+func WithCredentials(provider CredentialProvider) Option {
+	return func(c *Client) {
+		c.credentials = provider
+	}
+}
+
+// StaticCredentials is a fixed subscription key.
+type StaticCredentials string
+
+// APIKey implements CredentialProvider.
+func (s StaticCredentials) APIKey(ctx context.Context) (string, error) {
+	if s == "" {
+		return "", ErrNoCredentials
+	}
+
+	return string(s), nil
+}
+
+// EnvCredentials reads the subscription key from the environment variable
+// it names, on every request.
+type EnvCredentials string
+
+// APIKey implements CredentialProvider.
+func (e EnvCredentials) APIKey(ctx context.Context) (string, error) {
+	key := strings.TrimSpace(os.Getenv(string(e)))
+	if key == "" {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrNoCredentials, string(e))
+	}
+
+	return key, nil
+}
+
+// FileCredentials reads the subscription key from a file, re-reading it
+// whenever the file's modification time or size changes. Surrounding
+// whitespace is ignored.
+type FileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	key     string
+	modTime time.Time
+	size    int64
+}
+
+// NewFileCredentials returns a provider reading the key from path.
+func NewFileCredentials(path string) *FileCredentials {
+	return &FileCredentials{path: path}
+}
+
+// APIKey implements CredentialProvider.
+func (f *FileCredentials) APIKey(ctx context.Context) (string, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat credentials file: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.key == "" || !info.ModTime().Equal(f.modTime) || info.Size() != f.size {
+		if err := f.load(); err != nil {
+			return "", err
+		}
+	}
+
+	return f.key, nil
+}
+
+// Refresh implements CredentialRefresher by re-reading the file.
+func (f *FileCredentials) Refresh(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.load()
+}
+
+// load reads the key from the file. f.mu must be held.
+func (f *FileCredentials) load() error {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat credentials file: %w", err)
+	}
+
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	key := strings.TrimSpace(string(data))
+	if key == "" {
+		return fmt.Errorf("%w: credentials file %s is empty", ErrNoCredentials, f.path)
+	}
+
+	f.key, f.modTime, f.size = key, info.ModTime(), info.Size()
+
+	return nil
+}
+
+// ChainCredentials asks each provider in turn and uses the first key found.
+type ChainCredentials []CredentialProvider
+
+// APIKey implements CredentialProvider.
+func (ch ChainCredentials) APIKey(ctx context.Context) (string, error) {
+	var errs []error
+	for _, provider := range ch {
+		key, err := provider.APIKey(ctx)
+		if err == nil {
+			return key, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return "", ErrNoCredentials
+	}
+
+	return "", errors.Join(errs...)
+}
+
+// Refresh implements CredentialRefresher by refreshing every provider in
+// the chain that supports it. It fails only if all of them fail.
+func (ch ChainCredentials) Refresh(ctx context.Context) error {
+	var errs []error
+	refreshed := false
+	for _, provider := range ch {
+		refresher, ok := provider.(CredentialRefresher)
+		if !ok {
+			continue
+		}
+
+		if err := refresher.Refresh(ctx); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		refreshed = true
+	}
+
+	if refreshed {
+		return nil
+	}
+
+	if len(errs) == 0 {
+		return fmt.Errorf("%w: no provider in the chain can be refreshed", ErrNoCredentials)
+	}
+
+	return errors.Join(errs...)
+}
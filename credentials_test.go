@@ -0,0 +1,185 @@
+package chipotle_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+)
+
+// writeKey writes key to path.
+func writeKey(t *testing.T, path, key string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(key+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// rotateKey rewrites path with key, which must have the length of the old
+// key, keeping its modification time so FileCredentials can't tell it
+// changed until it is refreshed.
+func rotateKey(t *testing.T, path, key string) {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeKey(t, path, key)
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCredentialRefresh(t *testing.T) {
+	tests := []struct {
+		name string
+		// serverKey is the key the server requires once the file holds
+		// rotatedKey
+		serverKey    string
+		rotatedKey   string
+		static       bool
+		wantErr      error
+		wantRequests int
+	}{
+		{name: "rotated key", serverKey: "key-two", rotatedKey: "key-two", wantRequests: 2},
+		{name: "still rejected", serverKey: "key-333", rotatedKey: "key-two", wantErr: chipotle.ErrUnauthorized, wantRequests: 2},
+		{name: "not refreshable", serverKey: "key-two", static: true, wantErr: chipotle.ErrUnauthorized, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 1)
+			s.RequireAPIKey("key-one")
+
+			path := filepath.Join(t.TempDir(), "key")
+			writeKey(t, path, "key-one")
+			var provider chipotle.CredentialProvider = chipotle.NewFileCredentials(path)
+			if tt.static {
+				provider = chipotle.StaticCredentials("key-one")
+			}
+			c := newClient(s, chipotle.WithCredentials(provider))
+
+			if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+				t.Fatalf("GetMenuContext() with the first key error = %v", err)
+			}
+
+			s.RequireAPIKey(tt.serverKey)
+			if tt.rotatedKey != "" {
+				rotateKey(t, path, tt.rotatedKey)
+			}
+
+			_, err := c.GetMenuContext(context.Background(), 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetMenuContext() error = %v, want %v", err, tt.wantErr)
+			}
+			// the first key's request, then one refreshed retry at most
+			if got := s.Requests(chipotle.EndpointMenu) - 1; got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestFileCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	f := chipotle.NewFileCredentials(path)
+
+	if _, err := f.APIKey(context.Background()); err == nil {
+		t.Error("APIKey() without a file error = nil, want an error")
+	}
+
+	writeKey(t, path, "  key-one  ")
+	if key, err := f.APIKey(context.Background()); err != nil || key != "key-one" {
+		t.Errorf("APIKey() = %q, %v, want %q", key, err, "key-one")
+	}
+
+	// a rewritten file is picked up without a refresh
+	writeKey(t, path, "rotated-key")
+	if key, err := f.APIKey(context.Background()); err != nil || key != "rotated-key" {
+		t.Errorf("APIKey() after rewriting = %q, %v, want %q", key, err, "rotated-key")
+	}
+
+	writeKey(t, path, "")
+	if _, err := f.APIKey(context.Background()); !errors.Is(err, chipotle.ErrNoCredentials) {
+		t.Errorf("APIKey() with an empty file error = %v, want %v", err, chipotle.ErrNoCredentials)
+	}
+}
+
+func TestEnvCredentials(t *testing.T) {
+	const name = "CHIPOTLE_TEST_API_KEY"
+	e := chipotle.EnvCredentials(name)
+
+	t.Setenv(name, " env-key\n")
+	if key, err := e.APIKey(context.Background()); err != nil || key != "env-key" {
+		t.Errorf("APIKey() = %q, %v, want %q", key, err, "env-key")
+	}
+
+	t.Setenv(name, "")
+	if _, err := e.APIKey(context.Background()); !errors.Is(err, chipotle.ErrNoCredentials) {
+		t.Errorf("APIKey() when unset error = %v, want %v", err, chipotle.ErrNoCredentials)
+	}
+}
+
+func TestChainCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key")
+	writeKey(t, path, "file-key")
+	t.Setenv("CHIPOTLE_TEST_API_KEY", "")
+
+	unset := chipotle.EnvCredentials("CHIPOTLE_TEST_API_KEY")
+	missing := chipotle.NewFileCredentials(filepath.Join(t.TempDir(), "missing"))
+
+	tests := []struct {
+		name           string
+		chain          chipotle.ChainCredentials
+		wantKey        string
+		wantErr        error
+		wantRefreshErr bool
+	}{
+		{
+			name:    "first provider",
+			chain:   chipotle.ChainCredentials{chipotle.StaticCredentials("static-key"), chipotle.NewFileCredentials(path)},
+			wantKey: "static-key",
+		},
+		{
+			name:    "falls back",
+			chain:   chipotle.ChainCredentials{unset, missing, chipotle.NewFileCredentials(path)},
+			wantKey: "file-key",
+		},
+		{
+			name:           "none found",
+			chain:          chipotle.ChainCredentials{unset, chipotle.StaticCredentials("")},
+			wantErr:        chipotle.ErrNoCredentials,
+			wantRefreshErr: true,
+		},
+		{
+			name:           "empty",
+			wantErr:        chipotle.ErrNoCredentials,
+			wantRefreshErr: true,
+		},
+		{
+			name:           "refresh fails everywhere",
+			chain:          chipotle.ChainCredentials{missing, unset, chipotle.StaticCredentials("static-key")},
+			wantKey:        "static-key",
+			wantRefreshErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.chain.APIKey(context.Background())
+			if !errors.Is(err, tt.wantErr) || key != tt.wantKey {
+				t.Errorf("APIKey() = %q, %v, want %q, %v", key, err, tt.wantKey, tt.wantErr)
+			}
+
+			// refreshing succeeds if any provider in the chain refreshes
+			if err := tt.chain.Refresh(context.Background()); (err != nil) != tt.wantRefreshErr {
+				t.Errorf("Refresh() error = %v, want error %v", err, tt.wantRefreshErr)
+			}
+		})
+	}
+}
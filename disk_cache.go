@@ -0,0 +1,150 @@
+package chipotle
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DiskCache is a Cache that keeps one file per entry in a directory, so
+// cached responses survive restarts. Each file starts with the expiry time
+// and the key on their own lines, followed by the value.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DiskCache storing entries in dir, creating it if
+// needed.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{dir: dir}, nil
+}
+
+// Get implements Cache.
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	expires, storedKey, value, ok := parseDiskCacheEntry(data)
+	if !ok || storedKey != key {
+		return nil, false
+	}
+
+	if time.Now().After(expires) {
+		os.Remove(d.path(key))
+		return nil, false
+	}
+
+	return value, true
+}
+
+// Set implements Cache. Errors writing the entry are ignored; the value is
+// simply not cached.
+func (d *DiskCache) Set(key string, value []byte, ttl time.Duration) {
+	var buf bytes.Buffer
+	buf.WriteString(strconv.FormatInt(time.Now().Add(ttl).UnixNano(), 10))
+	buf.WriteByte('\n')
+	buf.WriteString(key)
+	buf.WriteByte('\n')
+	buf.Write(value)
+
+	// write to a temporary file and rename it, so readers never see a
+	// partial entry
+	tmp, err := os.CreateTemp(d.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+
+	_, err = tmp.Write(buf.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil || os.Rename(tmp.Name(), d.path(key)) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete implements Cache.
+func (d *DiskCache) Delete(key string) {
+	os.Remove(d.path(key))
+}
+
+// DeletePrefix implements Cache. It reads the key of every entry, so it is
+// proportional to the size of the cache.
+func (d *DiskCache) DeletePrefix(prefix string) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			continue
+		}
+
+		path := filepath.Join(d.dir, entry.Name())
+		if key, ok := readDiskCacheKey(path); ok && strings.HasPrefix(key, prefix) {
+			os.Remove(path)
+		}
+	}
+}
+
+// path names the file for key after its hash, since keys contain
+// characters that aren't valid in file names.
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+func parseDiskCacheEntry(data []byte) (time.Time, string, []byte, bool) {
+	expiresLine, rest, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return time.Time{}, "", nil, false
+	}
+
+	key, value, ok := bytes.Cut(rest, []byte("\n"))
+	if !ok {
+		return time.Time{}, "", nil, false
+	}
+
+	expires, err := strconv.ParseInt(string(expiresLine), 10, 64)
+	if err != nil {
+		return time.Time{}, "", nil, false
+	}
+
+	return time.Unix(0, expires), string(key), value, true
+}
+
+// readDiskCacheKey reads only the header of the entry at path.
+func readDiskCacheKey(path string) (string, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", false
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if _, err := r.ReadString('\n'); err != nil {
+		return "", false
+	}
+
+	key, err := r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", false
+	}
+
+	return strings.TrimSuffix(key, "\n"), true
+}
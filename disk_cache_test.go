@@ -0,0 +1,91 @@
+package chipotle_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+)
+
+func TestDiskCache(t *testing.T) {
+	testCache(t, func(t *testing.T) chipotle.Cache {
+		c, err := chipotle.NewDiskCache(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewDiskCache() error = %v", err)
+		}
+		return c
+	})
+}
+
+func TestDiskCachePersists(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+
+	first, err := chipotle.NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	first.Set("menu /1", []byte("one"), time.Minute)
+
+	second, err := chipotle.NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+	if value, ok := second.Get("menu /1"); !ok || string(value) != "one" {
+		t.Errorf("Get() = %q, %v, want %q, true", value, ok, "one")
+	}
+}
+
+func TestDiskCacheCorruptEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "empty", content: ""},
+		{name: "no key", content: "123"},
+		{name: "bad expiry", content: "soon\nmenu /1\none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			c, err := chipotle.NewDiskCache(dir)
+			if err != nil {
+				t.Fatalf("NewDiskCache() error = %v", err)
+			}
+			c.Set("menu /1", []byte("one"), time.Minute)
+
+			// overwrite the one entry on disk
+			entries, err := os.ReadDir(dir)
+			if err != nil || len(entries) != 1 {
+				t.Fatalf("ReadDir() = %v, %v, want one entry", entries, err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, entries[0].Name()), []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			if value, ok := c.Get("menu /1"); ok {
+				t.Errorf("Get() = %q, true, want a miss", value)
+			}
+		})
+	}
+}
+
+func TestDiskCacheIgnoresTemporaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	c, err := chipotle.NewDiskCache(dir)
+	if err != nil {
+		t.Fatalf("NewDiskCache() error = %v", err)
+	}
+
+	tmp := filepath.Join(dir, ".tmp-123")
+	if err := os.WriteFile(tmp, []byte("partial"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c.DeletePrefix("")
+
+	if _, err := os.Stat(tmp); err != nil {
+		t.Errorf("DeletePrefix removed a temporary file: %v", err)
+	}
+}
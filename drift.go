@@ -0,0 +1,261 @@
+package chipotle
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxDriftSampleLen bounds the length of the sample kept for each drift.
+const maxDriftSampleLen = 200
+
+// DriftKind says how a response differs from the models.
+type DriftKind string
+
+const (
+	// DriftUnknownField is a field the models don't have.
+	DriftUnknownField DriftKind = "unknown"
+	// DriftChangedType is a field whose JSON type no longer matches the
+	// model, e.g. a string where a number was expected.
+	DriftChangedType DriftKind = "changed"
+)
+
+// SchemaDrift describes one place where responses differ from the models.
+type SchemaDrift struct {
+	// Endpoint is the endpoint whose responses drifted.
+	Endpoint Endpoint
+	// Kind says how the field drifted.
+	Kind DriftKind
+	// Path locates the field, e.g. "entrees[].contentGroups[].newField".
+	Path string
+	// Sample is the JSON of the first value seen, truncated.
+	Sample string
+	// Count is the number of times the field has been seen. In
+	// Client.SchemaDrift, each upstream response counts once, however
+	// many callers it was served to from the cache or by deduplication.
+	Count int
+}
+
+// WithSchemaDriftDetection makes the client compare every response with
+// the models it is decoded into. Unknown fields and fields whose type
+// changed are reported alongside results, in the Drift fields of
+// MenuResponse, SearchPageResponse and MenuResult, and accumulated in
+// Client.SchemaDrift instead of being dropped silently. Type changes no
+// longer fail the call; the affected fields are left at their zero value.
+func WithSchemaDriftDetection() Option {
+	return func(c *Client) {
+		c.drift = &driftLog{seen: map[string]*SchemaDrift{}}
+	}
+}
+
+// SchemaDrift returns every drift seen so far, sorted by endpoint and path.
+// It is empty unless WithSchemaDriftDetection is set.
+func (c *Client) SchemaDrift() []SchemaDrift {
+	if c.drift == nil {
+		return nil
+	}
+
+	return c.drift.snapshot()
+}
+
+// driftLog accumulates drift across calls.
+type driftLog struct {
+	mu   sync.Mutex
+	seen map[string]*SchemaDrift
+}
+
+// add merges drifts into the log and returns the ones seen for the first
+// time.
+func (l *driftLog) add(drifts []SchemaDrift) []SchemaDrift {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var added []SchemaDrift
+	for _, d := range drifts {
+		key := string(d.Endpoint) + " " + string(d.Kind) + " " + d.Path
+		if existing, ok := l.seen[key]; ok {
+			existing.Count += d.Count
+			continue
+		}
+
+		d := d
+		l.seen[key] = &d
+		added = append(added, d)
+	}
+
+	return added
+}
+
+func (l *driftLog) snapshot() []SchemaDrift {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	drifts := make([]SchemaDrift, 0, len(l.seen))
+	for _, d := range l.seen {
+		drifts = append(drifts, *d)
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Endpoint != drifts[j].Endpoint {
+			return drifts[i].Endpoint < drifts[j].Endpoint
+		}
+		return drifts[i].Path < drifts[j].Path
+	})
+
+	return drifts
+}
+
+// decodeWithDrift decodes body into out like json.Unmarshal, but tolerates
+// type mismatches and reports how body differs from out's type.
+func decodeWithDrift(endpoint Endpoint, body []byte, out any) ([]SchemaDrift, error) {
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(body, out); err != nil && !errors.As(err, &typeErr) {
+		return nil, err
+	}
+
+	var generic any
+	if err := json.Unmarshal(body, &generic); err != nil {
+		return nil, err
+	}
+
+	d := &driftDetector{endpoint: endpoint, found: map[string]*SchemaDrift{}}
+	d.walk(reflect.TypeOf(out), generic, "")
+
+	drifts := make([]SchemaDrift, 0, len(d.found))
+	for _, drift := range d.found {
+		drifts = append(drifts, *drift)
+	}
+
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Path < drifts[j].Path })
+
+	return drifts, nil
+}
+
+// driftDetector walks a generic JSON value alongside the Go type it is
+// decoded into.
+type driftDetector struct {
+	endpoint Endpoint
+	found    map[string]*SchemaDrift
+}
+
+func (d *driftDetector) walk(t reflect.Type, v any, path string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if v == nil {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Interface, reflect.Map:
+		// anything goes
+
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			d.report(DriftChangedType, path, v)
+			return
+		}
+
+		fields := jsonFields(t)
+		for key, value := range obj {
+			fieldType, ok := fields[strings.ToLower(key)]
+			if !ok {
+				d.report(DriftUnknownField, joinPath(path, key), value)
+				continue
+			}
+
+			d.walk(fieldType, value, joinPath(path, key))
+		}
+
+	case reflect.Slice, reflect.Array:
+		items, ok := v.([]any)
+		if !ok {
+			d.report(DriftChangedType, path, v)
+			return
+		}
+
+		for _, item := range items {
+			d.walk(t.Elem(), item, path+"[]")
+		}
+
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			d.report(DriftChangedType, path, v)
+		}
+
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			d.report(DriftChangedType, path, v)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := v.(float64)
+		if !ok || n != float64(int64(n)) {
+			d.report(DriftChangedType, path, v)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if _, ok := v.(float64); !ok {
+			d.report(DriftChangedType, path, v)
+		}
+	}
+}
+
+func (d *driftDetector) report(kind DriftKind, path string, sample any) {
+	key := string(kind) + " " + path
+	if drift, ok := d.found[key]; ok {
+		drift.Count++
+		return
+	}
+
+	data, _ := json.Marshal(sample)
+	if len(data) > maxDriftSampleLen {
+		data = append(data[:maxDriftSampleLen], "..."...)
+	}
+
+	d.found[key] = &SchemaDrift{
+		Endpoint: d.endpoint,
+		Kind:     kind,
+		Path:     path,
+		Sample:   string(data),
+		Count:    1,
+	}
+}
+
+// jsonFields maps the lowercased JSON names of a struct's fields to their
+// types, matching case-insensitively like encoding/json.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fields[strings.ToLower(name)] = f.Type
+	}
+
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,133 @@
+package chipotle_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+)
+
+// newMenuServer serves body as the menu of every restaurant.
+func newMenuServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestSchemaDrift(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantDrift []chipotle.SchemaDrift
+		wantSides int
+	}{
+		{
+			name:      "none",
+			body:      `{"restaurantId":1,"sides":[{"itemName":"Chips"}]}`,
+			wantSides: 1,
+		},
+		{
+			name: "unknown field",
+			body: `{"restaurantId":1,"loyalty":{"points":10},"sides":[{"itemName":"Chips"}]}`,
+			wantDrift: []chipotle.SchemaDrift{
+				{Endpoint: chipotle.EndpointMenu, Kind: chipotle.DriftUnknownField, Path: "loyalty", Sample: `{"points":10}`, Count: 1},
+			},
+			wantSides: 1,
+		},
+		{
+			name: "unknown field in every item",
+			body: `{"restaurantId":1,"sides":[{"itemName":"Chips","spicy":true},{"itemName":"Guac","spicy":false}]}`,
+			wantDrift: []chipotle.SchemaDrift{
+				{Endpoint: chipotle.EndpointMenu, Kind: chipotle.DriftUnknownField, Path: "sides[].spicy", Sample: `true`, Count: 2},
+			},
+			wantSides: 2,
+		},
+		{
+			name: "changed type",
+			body: `{"restaurantId":"1","sides":[{"itemName":"Chips"}]}`,
+			wantDrift: []chipotle.SchemaDrift{
+				{Endpoint: chipotle.EndpointMenu, Kind: chipotle.DriftChangedType, Path: "restaurantId", Sample: `"1"`, Count: 1},
+			},
+			wantSides: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newMenuServer(t, tt.body)
+			c := chipotle.NewClient("test-key", chipotle.WithBaseURL(s.URL), chipotle.WithSchemaDriftDetection())
+
+			resp, err := c.GetMenuRaw(context.Background(), 1)
+			if err != nil {
+				t.Fatalf("GetMenuRaw() error = %v", err)
+			}
+			if got := len(resp.Menu.Sides); got != tt.wantSides {
+				t.Errorf("got %d sides, want %d", got, tt.wantSides)
+			}
+
+			if (len(resp.Drift) > 0 || len(tt.wantDrift) > 0) && !reflect.DeepEqual(resp.Drift, tt.wantDrift) {
+				t.Errorf("Drift = %+v, want %+v", resp.Drift, tt.wantDrift)
+			}
+			if got := c.SchemaDrift(); len(got) != len(tt.wantDrift) {
+				t.Errorf("SchemaDrift() = %+v, want %d drifts", got, len(tt.wantDrift))
+			}
+		})
+	}
+}
+
+func TestSchemaDriftCountedOncePerResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []chipotle.Option
+		wantCount int
+	}{
+		{name: "uncached", wantCount: 3},
+		{name: "cached", opts: []chipotle.Option{chipotle.WithCache(chipotle.NewMemoryCache(0))}, wantCount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newMenuServer(t, `{"restaurantId":1,"loyalty":true}`)
+			opts := append([]chipotle.Option{chipotle.WithBaseURL(s.URL), chipotle.WithSchemaDriftDetection()}, tt.opts...)
+			c := chipotle.NewClient("test-key", opts...)
+
+			for i := 0; i < 3; i++ {
+				resp, err := c.GetMenuRaw(context.Background(), 1)
+				if err != nil {
+					t.Fatalf("GetMenuRaw() error = %v", err)
+				}
+				// every caller sees the drift of its response
+				if len(resp.Drift) != 1 {
+					t.Errorf("call %d: Drift = %+v, want one drift", i, resp.Drift)
+				}
+			}
+
+			drift := c.SchemaDrift()
+			if len(drift) != 1 || drift[0].Count != tt.wantCount {
+				t.Errorf("SchemaDrift() = %+v, want one drift seen %d times", drift, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestSchemaDriftDisabled(t *testing.T) {
+	s := newMenuServer(t, `{"restaurantId":"1"}`)
+	c := chipotle.NewClient("test-key", chipotle.WithBaseURL(s.URL))
+
+	// without detection, a changed type fails the call as before
+	if _, err := c.GetMenuRaw(context.Background(), 1); err == nil {
+		t.Error("GetMenuRaw() error = nil, want a decoding error")
+	}
+	if got := c.SchemaDrift(); got != nil {
+		t.Errorf("SchemaDrift() = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,109 @@
+package chipotle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Sentinel errors matched by APIError through errors.Is.
+var (
+	// ErrNotFound means the resource does not exist, e.g. a restaurant
+	// without an online menu.
+	ErrNotFound = errors.New("chipotle: not found")
+	// ErrUnauthorized means the subscription key was missing or rejected.
+	ErrUnauthorized = errors.New("chipotle: unauthorized")
+	// ErrRateLimited means the API throttled the request.
+	ErrRateLimited = errors.New("chipotle: rate limited")
+)
+
+// requestIDHeaders are the response headers that may carry an identifier
+// for the request, in order of preference.
+var requestIDHeaders = []string{
+	"X-Request-Id",
+	"Request-Id",
+	"Apim-Request-Id",
+	"X-Correlation-Id",
+	"X-Ms-Request-Id",
+}
+
+// ErrorBody is the error payload returned by the API.
+type ErrorBody struct {
+	StatusCode int    `json:"statusCode,omitempty"`
+	Message    string `json:"message,omitempty"`
+	Errors     []struct {
+		Code    string `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	} `json:"errors,omitempty"`
+}
+
+// APIError is returned when the API answers with a non-200 status.
+type APIError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Endpoint is the endpoint that was called.
+	Endpoint Endpoint
+	// RequestID identifies the request in upstream logs, if the response
+	// carried one of the usual request ID headers.
+	RequestID string
+	// Header holds the response headers.
+	Header http.Header
+	// Body is the raw response body.
+	Body []byte
+	// ErrorBody is the decoded body, or nil if it wasn't an error payload.
+	ErrorBody *ErrorBody
+}
+
+func newAPIError(endpoint Endpoint, resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Endpoint:   endpoint,
+		Header:     resp.Header,
+		Body:       body,
+	}
+
+	for _, header := range requestIDHeaders {
+		if id := resp.Header.Get(header); id != "" {
+			apiErr.RequestID = id
+			break
+		}
+	}
+
+	var errBody ErrorBody
+	if err := json.Unmarshal(body, &errBody); err == nil && (errBody.Message != "" || len(errBody.Errors) > 0) {
+		apiErr.ErrorBody = &errBody
+	}
+
+	return apiErr
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	msg := string(e.Body)
+	if e.ErrorBody != nil && e.ErrorBody.Message != "" {
+		msg = e.ErrorBody.Message
+	} else if e.ErrorBody != nil {
+		msg = e.ErrorBody.Errors[0].Message
+	}
+
+	if e.RequestID != "" {
+		return fmt.Sprintf("chipotle: %s: unexpected status code: %d, %s (request id %s)", e.Endpoint, e.StatusCode, msg, e.RequestID)
+	}
+
+	return fmt.Sprintf("chipotle: %s: unexpected status code: %d, %s", e.Endpoint, e.StatusCode, msg)
+}
+
+// Is matches the sentinel error corresponding to the status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package chipotle_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+)
+
+func TestAPIError(t *testing.T) {
+	sentinels := []error{chipotle.ErrNotFound, chipotle.ErrUnauthorized, chipotle.ErrRateLimited}
+
+	tests := []struct {
+		name          string
+		status        int
+		header        map[string]string
+		body          string
+		wantSentinel  error
+		wantRequestID string
+		wantMessage   string
+		wantErrorBody bool
+	}{
+		{
+			name:          "not found",
+			status:        http.StatusNotFound,
+			header:        map[string]string{"X-Request-Id": "req-1"},
+			body:          `{"statusCode":404,"message":"Resource not found"}`,
+			wantSentinel:  chipotle.ErrNotFound,
+			wantRequestID: "req-1",
+			wantMessage:   "Resource not found",
+			wantErrorBody: true,
+		},
+		{
+			name:          "unauthorized",
+			status:        http.StatusUnauthorized,
+			header:        map[string]string{"Apim-Request-Id": "req-2"},
+			body:          `{"statusCode":401,"message":"Access denied due to invalid subscription key."}`,
+			wantSentinel:  chipotle.ErrUnauthorized,
+			wantRequestID: "req-2",
+			wantMessage:   "Access denied due to invalid subscription key.",
+			wantErrorBody: true,
+		},
+		{
+			name:         "forbidden",
+			status:       http.StatusForbidden,
+			body:         `forbidden`,
+			wantSentinel: chipotle.ErrUnauthorized,
+			wantMessage:  "forbidden",
+		},
+		{
+			name:         "rate limited",
+			status:       http.StatusTooManyRequests,
+			wantSentinel: chipotle.ErrRateLimited,
+		},
+		{
+			name:          "errors list",
+			status:        http.StatusBadRequest,
+			body:          `{"errors":[{"code":"InvalidRadius","message":"Radius is out of range"}]}`,
+			wantMessage:   "Radius is out of range",
+			wantErrorBody: true,
+		},
+		{
+			name:        "plain body",
+			status:      http.StatusInternalServerError,
+			body:        `upstream timed out`,
+			wantMessage: "upstream timed out",
+		},
+		{
+			name:          "preferred request id",
+			status:        http.StatusBadGateway,
+			header:        map[string]string{"X-Correlation-Id": "corr-1", "Request-Id": "req-3"},
+			wantRequestID: "req-3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tt.header {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer s.Close()
+			c := chipotle.NewClient("test-key", chipotle.WithBaseURL(s.URL), chipotle.WithRetryPolicy(chipotle.RetryPolicy{}))
+
+			_, err := c.GetMenuContext(context.Background(), 1)
+
+			var apiErr *chipotle.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GetMenuContext() error = %v, want an APIError", err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Endpoint != chipotle.EndpointMenu || string(apiErr.Body) != tt.body {
+				t.Errorf("APIError = %+v, want status %d from the menu endpoint", apiErr, tt.status)
+			}
+			if apiErr.RequestID != tt.wantRequestID {
+				t.Errorf("RequestID = %q, want %q", apiErr.RequestID, tt.wantRequestID)
+			}
+			if (apiErr.ErrorBody != nil) != tt.wantErrorBody {
+				t.Errorf("ErrorBody = %+v, want decoded %v", apiErr.ErrorBody, tt.wantErrorBody)
+			}
+			if !strings.Contains(err.Error(), tt.wantMessage) || !strings.Contains(err.Error(), tt.wantRequestID) {
+				t.Errorf("Error() = %q, want it to mention %q and %q", err, tt.wantMessage, tt.wantRequestID)
+			}
+
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == tt.wantSentinel) {
+					t.Errorf("errors.Is(err, %v) = %v", sentinel, got)
+				}
+			}
+		})
+	}
+}
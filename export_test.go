@@ -0,0 +1,25 @@
+package chipotle
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Backoff exposes RetryPolicy.backoff to the external tests.
+func (p RetryPolicy) Backoff(n int) time.Duration {
+	return p.backoff(n)
+}
+
+// BreakerTicket exposes breakerTicket to the external tests.
+type BreakerTicket = breakerTicket
+
+// Allow exposes CircuitBreaker.allow to the external tests.
+func (b *CircuitBreaker) Allow() (BreakerTicket, error) {
+	return b.allow()
+}
+
+// Record exposes CircuitBreaker.record to the external tests.
+func (b *CircuitBreaker) Record(ticket BreakerTicket, resp *http.Response, err error) {
+	b.record(context.Background(), ticket, resp, err)
+}
@@ -0,0 +1,69 @@
+package chipotle_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+	"github.com/kylegrantlucas/chipotle-go/menu"
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+// newServer starts a fake API server holding n open restaurants around
+// Folsom, CA, numbered from 1, and a menu for each.
+func newServer(t *testing.T, n int) *chipotletest.Server {
+	t.Helper()
+
+	s := chipotletest.NewServer()
+	t.Cleanup(s.Close)
+
+	for i := 1; i <= n; i++ {
+		s.AddRestaurants(restaurant.Restaurant{
+			RestaurantNumber: i,
+			RestaurantStatus: string(search.StatusOpen),
+			Addresses: []restaurant.Address{{
+				AddressType: string(search.AddressMain),
+				Latitude:    38.67 + float64(i)*0.001,
+				Longitude:   -121.17,
+			}},
+		})
+		s.SetMenu(&menu.Menu{
+			RestaurantID: i,
+			Sides:        []menu.Side{{ItemName: "Chips", IsItemAvailable: true}},
+		})
+	}
+
+	return s
+}
+
+// newClient returns a client for s that retries quickly.
+func newClient(s *chipotletest.Server, opts ...chipotle.Option) *chipotle.Client {
+	opts = append([]chipotle.Option{
+		chipotle.WithBaseURL(s.URL),
+		chipotle.WithRetryPolicy(fastRetries(3)),
+	}, opts...)
+
+	return chipotle.NewClient("test-key", opts...)
+}
+
+// fastRetries returns the default retry policy with attempts attempts and
+// millisecond backoff.
+func fastRetries(attempts int) chipotle.RetryPolicy {
+	policy := chipotle.DefaultRetryPolicy()
+	policy.MaxAttempts = attempts
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+
+	return policy
+}
+
+// nearby is a query matching the restaurants of newServer.
+func nearby() search.Query {
+	return search.NewQuery().
+		Near(38.67, -121.17).
+		Within(50 * search.Kilometer).
+		Statuses(search.StatusOpen).
+		EmbedAll()
+}
@@ -0,0 +1,34 @@
+package chipotle
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger makes the client log every request, response, retry and cache
+// hit to logger at debug level, and problems such as schema drift at warn
+// level. Clients log nothing by default.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// debug logs msg at debug level, if the client has a logger and it is
+// enabled for debug.
+func (c *Client) debug(ctx context.Context, msg string, args ...any) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	c.logger.DebugContext(ctx, msg, args...)
+}
+
+// warn logs msg at warn level, if the client has a logger.
+func (c *Client) warn(ctx context.Context, msg string, args ...any) {
+	if c.logger == nil {
+		return
+	}
+
+	c.logger.WarnContext(ctx, msg, args...)
+}
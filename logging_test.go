@@ -0,0 +1,120 @@
+package chipotle_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+)
+
+// newLogger returns a logger writing JSON records at level and above to
+// buf.
+func newLogger(buf *bytes.Buffer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level}))
+}
+
+// logRecords decodes the JSON records written by a logger from newLogger.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("decoding log record: %v", err)
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestWithLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   slog.Level
+		wantMsg []string
+	}{
+		{
+			name:  "debug",
+			level: slog.LevelDebug,
+			wantMsg: []string{
+				"chipotle: sending request",
+				"chipotle: received response",
+				"chipotle: retrying request",
+				"chipotle: sending request",
+				"chipotle: received response",
+				"chipotle: serving response from cache",
+			},
+		},
+		{
+			name:  "info",
+			level: slog.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			s := newServer(t, 1)
+			s.InjectFault(chipotletest.Fault{Endpoint: chipotle.EndpointMenu, StatusCode: http.StatusServiceUnavailable, Times: 1})
+			c := newClient(s,
+				chipotle.WithLogger(newLogger(&buf, tt.level)),
+				chipotle.WithCache(chipotle.NewMemoryCache(0)),
+			)
+
+			// a retried request, then a cache hit
+			for i := 0; i < 2; i++ {
+				if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+					t.Fatalf("GetMenuContext() error = %v", err)
+				}
+			}
+
+			var msgs []string
+			for _, record := range logRecords(t, &buf) {
+				msgs = append(msgs, record["msg"].(string))
+
+				if record["level"] != "DEBUG" || record["endpoint"] != "menu" {
+					t.Errorf("record %v, want a debug record for the menu endpoint", record)
+				}
+			}
+			if !slices.Equal(msgs, tt.wantMsg) {
+				t.Errorf("logged %q, want %q", msgs, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestWithLoggerWarnsOfDrift(t *testing.T) {
+	var buf bytes.Buffer
+	s := newMenuServer(t, `{"restaurantId":1,"loyalty":true}`)
+	c := chipotle.NewClient("test-key",
+		chipotle.WithBaseURL(s.URL),
+		chipotle.WithSchemaDriftDetection(),
+		chipotle.WithLogger(newLogger(&buf, slog.LevelWarn)),
+	)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+			t.Fatalf("GetMenuContext() error = %v", err)
+		}
+	}
+
+	// only the first sighting of the drift is logged
+	records := logRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("logged %v, want one record", records)
+	}
+
+	record := records[0]
+	if record["level"] != "WARN" || record["msg"] != "chipotle: response differs from model" || record["path"] != "loyalty" || record["kind"] != string(chipotle.DriftUnknownField) {
+		t.Errorf("logged %v, want a warning about the unknown loyalty field", record)
+	}
+}
@@ -0,0 +1,113 @@
+package chipotle
+
+import (
+	"bytes"
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// entry once it holds its maximum number of entries. Values are copied in
+// and out, so callers may modify them freely.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache holding up to maxEntries entries.
+// A non-positive maxEntries means no limit.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expires) {
+		m.remove(elem)
+		return nil, false
+	}
+
+	m.lru.MoveToFront(elem)
+
+	// hand out a copy, so callers can't modify the cached value
+	return bytes.Clone(entry.value), true
+}
+
+// Set implements Cache.
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryCacheEntry{key: key, value: bytes.Clone(value), expires: time.Now().Add(ttl)}
+	if elem, ok := m.entries[key]; ok {
+		elem.Value = entry
+		m.lru.MoveToFront(elem)
+		return
+	}
+
+	m.entries[key] = m.lru.PushFront(entry)
+
+	if m.maxEntries > 0 && m.lru.Len() > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+}
+
+// Delete implements Cache.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		m.remove(elem)
+	}
+}
+
+// DeletePrefix implements Cache.
+func (m *MemoryCache) DeletePrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, elem := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			m.remove(elem)
+		}
+	}
+}
+
+// Len returns the number of entries in the cache, including expired
+// entries that haven't been evicted yet.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.lru.Len()
+}
+
+// remove drops elem from the cache. m.mu must be held.
+func (m *MemoryCache) remove(elem *list.Element) {
+	m.lru.Remove(elem)
+	delete(m.entries, elem.Value.(*memoryCacheEntry).key)
+}
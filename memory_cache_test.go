@@ -0,0 +1,53 @@
+package chipotle_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+)
+
+func TestMemoryCache(t *testing.T) {
+	testCache(t, func(t *testing.T) chipotle.Cache {
+		return chipotle.NewMemoryCache(0)
+	})
+}
+
+func TestMemoryCacheEviction(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxEntries int
+		// touch is read after filling the cache, making it recently used
+		touch   string
+		wantLen int
+		evicted string
+	}{
+		{name: "unlimited", maxEntries: 0, wantLen: 4},
+		{name: "oldest evicted", maxEntries: 3, wantLen: 3, evicted: "key 0"},
+		{name: "recently used kept", maxEntries: 3, touch: "key 0", wantLen: 3, evicted: "key 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := chipotle.NewMemoryCache(tt.maxEntries)
+			for i := 0; i < 3; i++ {
+				c.Set(fmt.Sprintf("key %d", i), []byte("value"), time.Minute)
+			}
+			if tt.touch != "" {
+				c.Get(tt.touch)
+			}
+			c.Set("key 3", []byte("value"), time.Minute)
+
+			if got := c.Len(); got != tt.wantLen {
+				t.Errorf("Len() = %d, want %d", got, tt.wantLen)
+			}
+			for i := 0; i <= 3; i++ {
+				key := fmt.Sprintf("key %d", i)
+				if _, ok := c.Get(key); ok == (key == tt.evicted) {
+					t.Errorf("Get(%q) found = %v, want %v", key, ok, key != tt.evicted)
+				}
+			}
+		})
+	}
+}
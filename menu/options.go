@@ -0,0 +1,52 @@
+package menu
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Channel is the ordering channel a menu is requested for. Prices and
+// availability can differ between channels.
+type Channel string
+
+// Ordering channels. Only ChannelWeb, the channel the crawler has always
+// used, is confirmed to be accepted by the API; the others are unconfirmed
+// guesses at its channel IDs. Channel is sent verbatim, so other values can
+// be used as well.
+const (
+	ChannelWeb Channel = "web"
+	// ChannelMobile is unconfirmed.
+	ChannelMobile Channel = "mobile"
+	// ChannelDelivery is unconfirmed.
+	ChannelDelivery Channel = "delivery"
+	// ChannelKiosk is unconfirmed.
+	ChannelKiosk Channel = "kiosk"
+)
+
+// Options configures which variant of a menu is requested. The zero value
+// is the default: the web channel, including unavailable items.
+type Options struct {
+	// Channel selects the ordering channel. Empty means ChannelWeb.
+	Channel Channel
+	// ExcludeUnavailableItems leaves out the items the restaurant currently
+	// can't sell, which are otherwise returned with IsItemAvailable unset.
+	ExcludeUnavailableItems bool
+}
+
+// DefaultOptions returns the options used when none are given.
+func DefaultOptions() Options {
+	return Options{Channel: ChannelWeb}
+}
+
+// Values encodes o as the query parameters of a menu request.
+func (o Options) Values() url.Values {
+	channel := o.Channel
+	if channel == "" {
+		channel = ChannelWeb
+	}
+
+	return url.Values{
+		"channelId":               {string(channel)},
+		"includeUnavailableItems": {strconv.FormatBool(!o.ExcludeUnavailableItems)},
+	}
+}
@@ -0,0 +1,79 @@
+package chipotle
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kylegrantlucas/chipotle-go/menu"
+)
+
+// DefaultMenuConcurrency is the number of menus GetMenus fetches at once
+// when GetMenusOptions.Concurrency is not set.
+const DefaultMenuConcurrency = 8
+
+// GetMenusOptions configures GetMenus.
+type GetMenusOptions struct {
+	// Concurrency bounds the number of menus fetched at once.
+	Concurrency int
+	// Menu selects the variant of the menus to fetch.
+	Menu menu.Options
+}
+
+// MenuResult is the outcome of fetching one restaurant's menu.
+type MenuResult struct {
+	RestaurantID int
+	Menu         *menu.Menu
+	// Raw is the upstream payload the menu was decoded from.
+	Raw RawResponse
+	// Drift lists how Raw differs from menu.Menu, if schema drift
+	// detection is enabled.
+	Drift []SchemaDrift
+	Err   error
+}
+
+// GetMenus fetches the menus of restaurantIDs with bounded concurrency and
+// streams one MenuResult per restaurant, in completion order, over the
+// returned channel. A failed menu is reported in its result and does not
+// stop the batch. The channel is closed once every restaurant has been
+// reported; callers must drain it. Cancelling ctx makes the remaining
+// fetches fail fast with ctx's error.
+func (c *Client) GetMenus(ctx context.Context, restaurantIDs []int, opts GetMenusOptions) <-chan MenuResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultMenuConcurrency
+	}
+
+	ids := make(chan int)
+	results := make(chan MenuResult)
+
+	// Start the workers
+	var wg sync.WaitGroup
+	for i := 0; i < min(concurrency, len(restaurantIDs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				res := MenuResult{RestaurantID: id}
+				if resp, err := c.GetMenuWithOptions(ctx, id, opts.Menu); err != nil {
+					res.Err = err
+				} else {
+					res.Menu, res.Raw, res.Drift = resp.Menu, resp.Raw, resp.Drift
+				}
+				results <- res
+			}
+		}()
+	}
+
+	// Feed the workers, then close the results once they are all done
+	go func() {
+		for _, id := range restaurantIDs {
+			ids <- id
+		}
+		close(ids)
+
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,167 @@
+package chipotle_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+	"github.com/kylegrantlucas/chipotle-go/menu"
+)
+
+func TestGetMenus(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         []int
+		concurrency int
+		faults      []chipotletest.Fault
+		wantErrs    map[int]error
+	}{
+		{
+			name: "all found",
+			ids:  []int{1, 2, 3},
+		},
+		{
+			name:     "404 isolated",
+			ids:      []int{1, 404, 2, 3},
+			wantErrs: map[int]error{404: chipotle.ErrNotFound},
+		},
+		{
+			name:        "404 isolated without concurrency",
+			ids:         []int{1, 404, 2, 3},
+			concurrency: 1,
+			wantErrs:    map[int]error{404: chipotle.ErrNotFound},
+		},
+		{
+			name:        "server error isolated",
+			ids:         []int{1, 2, 3},
+			concurrency: 1,
+			faults: []chipotletest.Fault{
+				// the first request and its retries
+				{Endpoint: chipotle.EndpointMenu, StatusCode: http.StatusInternalServerError, Times: 3},
+			},
+			wantErrs: map[int]error{1: &chipotle.APIError{}},
+		},
+		{
+			name: "no restaurants",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 3)
+			for _, f := range tt.faults {
+				s.InjectFault(f)
+			}
+			c := newClient(s)
+
+			var got []int
+			for res := range c.GetMenus(context.Background(), tt.ids, chipotle.GetMenusOptions{Concurrency: tt.concurrency}) {
+				got = append(got, res.RestaurantID)
+
+				switch want := tt.wantErrs[res.RestaurantID].(type) {
+				case nil:
+					if res.Err != nil {
+						t.Errorf("restaurant %d: Err = %v", res.RestaurantID, res.Err)
+					} else if res.Menu.RestaurantID != res.RestaurantID {
+						t.Errorf("restaurant %d: got the menu of %d", res.RestaurantID, res.Menu.RestaurantID)
+					}
+				case *chipotle.APIError:
+					if !errors.As(res.Err, &want) {
+						t.Errorf("restaurant %d: Err = %v, want an APIError", res.RestaurantID, res.Err)
+					}
+				default:
+					if !errors.Is(res.Err, want) {
+						t.Errorf("restaurant %d: Err = %v, want %v", res.RestaurantID, res.Err, want)
+					}
+				}
+			}
+
+			// every restaurant is reported exactly once
+			want := append([]int(nil), tt.ids...)
+			sort.Ints(got)
+			sort.Ints(want)
+			if len(got) != len(want) {
+				t.Fatalf("got results for %v, want %v", got, want)
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Fatalf("got results for %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetMenusOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      menu.Options
+		wantSides int
+	}{
+		{name: "zero value includes unavailable items", wantSides: 2},
+		{name: "exclude unavailable items", opts: menu.Options{ExcludeUnavailableItems: true}, wantSides: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 0)
+			s.SetMenu(&menu.Menu{RestaurantID: 1, Sides: []menu.Side{
+				{ItemName: "Chips", IsItemAvailable: true},
+				{ItemName: "Queso"},
+			}})
+			c := newClient(s)
+
+			for res := range c.GetMenus(context.Background(), []int{1}, chipotle.GetMenusOptions{Menu: tt.opts}) {
+				if res.Err != nil {
+					t.Fatalf("Err = %v", res.Err)
+				}
+				if got := len(res.Menu.Sides); got != tt.wantSides {
+					t.Errorf("got %d sides, want %d", got, tt.wantSides)
+				}
+			}
+		})
+	}
+}
+
+func TestGetMenuWithOptionsQuery(t *testing.T) {
+	tests := []struct {
+		name                        string
+		opts                        menu.Options
+		wantChannel                 string
+		wantIncludeUnavailableItems string
+	}{
+		{name: "zero value", wantChannel: "web", wantIncludeUnavailableItems: "true"},
+		{name: "kiosk", opts: menu.Options{Channel: menu.ChannelKiosk}, wantChannel: "kiosk", wantIncludeUnavailableItems: "true"},
+		{name: "custom channel", opts: menu.Options{Channel: "catering"}, wantChannel: "catering", wantIncludeUnavailableItems: "true"},
+		{name: "exclude unavailable items", opts: menu.Options{Channel: menu.ChannelDelivery, ExcludeUnavailableItems: true}, wantChannel: "delivery", wantIncludeUnavailableItems: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query url.Values
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				w.Write([]byte(`{"restaurantId":1}`))
+			}))
+			defer s.Close()
+			c := chipotle.NewClient("test-key", chipotle.WithBaseURL(s.URL))
+
+			if _, err := c.GetMenuWithOptions(context.Background(), 1, tt.opts); err != nil {
+				t.Fatalf("GetMenuWithOptions() error = %v", err)
+			}
+
+			if got := query.Get("channelId"); got != tt.wantChannel {
+				t.Errorf("channelId = %q, want %q", got, tt.wantChannel)
+			}
+			if got := query.Get("includeUnavailableItems"); got != tt.wantIncludeUnavailableItems {
+				t.Errorf("includeUnavailableItems = %q, want %q", got, tt.wantIncludeUnavailableItems)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package chipotle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+// Request is an API call on its way through the middleware chain.
+// Middleware may modify it before passing it on, e.g. to add headers.
+type Request struct {
+	// Endpoint is the endpoint being called.
+	Endpoint Endpoint
+	// Method is the HTTP method.
+	Method string
+	// Path is the path relative to the base URL, including the query.
+	Path string
+	// Body is the request body, or nil.
+	Body []byte
+	// Header holds headers to send in addition to the client's defaults.
+	Header http.Header
+
+	// Query is the search being run, for EndpointSearch calls. Changing
+	// it has no effect on the request; Body is what gets sent.
+	Query *search.Query
+	// RestaurantID is the restaurant whose menu is fetched, for
+	// EndpointMenu calls.
+	RestaurantID int
+}
+
+// Response is the outcome of an API call.
+type Response struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Header holds the response headers. It is empty for cached responses.
+	Header http.Header
+	// Body is the raw response body.
+	Body []byte
+	// Result is the decoded body: a *search.Result for EndpointSearch or
+	// a *menu.Menu for EndpointMenu. Middleware that answers a call
+	// itself must set it to a value of that type.
+	Result any
+	// Cached reports whether the response was served from the cache.
+	Cached bool
+	// Attempts is the number of HTTP requests made, including retries.
+	Attempts int
+	// Shared reports whether the response came from an identical call
+	// already in flight for another caller.
+	Shared bool
+	// Drift lists how the body differed from the models, when schema
+	// drift detection is enabled.
+	Drift []SchemaDrift
+}
+
+// Handler performs an API call. The Handler at the end of the chain sends
+// the request, consulting the cache and retrying as configured, and
+// decodes the response into Response.Result.
+type Handler func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a Handler to add behavior around API calls, such as
+// logging, metrics, header signing or fault injection.
+type Middleware func(next Handler) Handler
+
+// WithMiddleware adds middleware to the client. The first middleware is
+// the outermost, seeing every call first and every response last.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// call runs req through the middleware chain, decoding the response body
+// into out.
+func (c *Client) call(ctx context.Context, req *Request, out any) (*Response, error) {
+	var h Handler = func(ctx context.Context, req *Request) (*Response, error) {
+		var resp *Response
+		var err error
+		if c.dedupes(req.Endpoint) {
+			key := cacheKey(req.Endpoint, req.Method, req.Path, req.Body)
+			resp, err = c.flights.do(ctx, key, func(ctx context.Context) (*Response, error) {
+				return c.fetch(ctx, req)
+			})
+		} else {
+			resp, err = c.fetch(ctx, req)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// decode response
+		if c.drift == nil {
+			if err := json.Unmarshal(resp.Body, out); err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+		} else {
+			drift, err := decodeWithDrift(req.Endpoint, resp.Body, out)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode response: %w", err)
+			}
+
+			resp.Drift = drift
+
+			// count each upstream body once, not again for every cache
+			// hit or deduplicated caller
+			if !resp.Cached && !resp.Shared {
+				for _, d := range c.drift.add(drift) {
+					c.warn(ctx, "chipotle: response differs from model", "endpoint", d.Endpoint, "kind", d.Kind, "path", d.Path, "sample", d.Sample)
+				}
+			}
+		}
+		resp.Result = out
+
+		// only cache bodies that decode, so a truncated response isn't
+		// served for the whole TTL
+		c.cacheResponse(req, resp)
+
+		return resp, nil
+	}
+
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		h = c.middleware[i](h)
+	}
+
+	// telemetry wraps everything, so spans cover the whole call
+	if c.tel != nil {
+		h = c.tel.middleware(h)
+	}
+
+	return h(ctx, req)
+}
@@ -0,0 +1,105 @@
+package chipotle_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/menu"
+)
+
+func TestWithMiddleware(t *testing.T) {
+	s := newServer(t, 1)
+
+	var mu sync.Mutex
+	var calls []string
+	record := func(name string) chipotle.Middleware {
+		return func(next chipotle.Handler) chipotle.Handler {
+			return func(ctx context.Context, req *chipotle.Request) (*chipotle.Response, error) {
+				mu.Lock()
+				calls = append(calls, name+" before")
+				mu.Unlock()
+
+				resp, err := next(ctx, req)
+
+				mu.Lock()
+				calls = append(calls, name+" after")
+				mu.Unlock()
+				return resp, err
+			}
+		}
+	}
+
+	c := newClient(s,
+		chipotle.WithMiddleware(record("outer"), record("middle")),
+		chipotle.WithMiddleware(record("inner")),
+	)
+	if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+		t.Fatalf("GetMenuContext() error = %v", err)
+	}
+
+	want := []string{"outer before", "middle before", "inner before", "inner after", "middle after", "outer after"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+}
+
+func TestMiddlewareModifiesRequest(t *testing.T) {
+	s := newServer(t, 2)
+
+	// send every menu request to restaurant 2 instead
+	redirect := func(next chipotle.Handler) chipotle.Handler {
+		return func(ctx context.Context, req *chipotle.Request) (*chipotle.Response, error) {
+			req.Path = "/menuinnovation/v1/restaurants/2/onlinemenu"
+			return next(ctx, req)
+		}
+	}
+
+	c := newClient(s, chipotle.WithMiddleware(redirect))
+	m, err := c.GetMenuContext(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetMenuContext() error = %v", err)
+	}
+	if m.RestaurantID != 2 {
+		t.Errorf("got the menu of %d, want 2", m.RestaurantID)
+	}
+}
+
+func TestMiddlewareAnswersCall(t *testing.T) {
+	s := newServer(t, 1)
+
+	// answer menu calls without reaching the server
+	stub := func(next chipotle.Handler) chipotle.Handler {
+		return func(ctx context.Context, req *chipotle.Request) (*chipotle.Response, error) {
+			if req.Endpoint != chipotle.EndpointMenu {
+				return next(ctx, req)
+			}
+
+			m := &menu.Menu{RestaurantID: req.RestaurantID, Sides: []menu.Side{{ItemName: "Stub"}}}
+			return &chipotle.Response{StatusCode: http.StatusOK, Result: m}, nil
+		}
+	}
+
+	c := newClient(s, chipotle.WithMiddleware(stub))
+	m, err := c.GetMenuContext(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetMenuContext() error = %v", err)
+	}
+	if len(m.Sides) != 1 || m.Sides[0].ItemName != "Stub" {
+		t.Errorf("GetMenuContext() = %+v, want the stubbed menu", m)
+	}
+	if got := s.Requests(chipotle.EndpointMenu); got != 0 {
+		t.Errorf("menu requests = %d, want 0", got)
+	}
+
+	// other endpoints still reach the server
+	if _, err := c.SearchContext(context.Background(), nearby()); err != nil {
+		t.Fatalf("SearchContext() error = %v", err)
+	}
+	if got := s.Requests(chipotle.EndpointSearch); got != 1 {
+		t.Errorf("search requests = %d, want 1", got)
+	}
+}
@@ -0,0 +1,53 @@
+package chipotle
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option configures a Client created by NewClient.
+type Option func(*Client)
+
+// WithBaseURL points the client at a different API host, such as a staging
+// environment or a local fake server. A trailing slash is ignored.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient uses httpClient for all requests. The client is copied, so
+// the default headers can be added without modifying the caller's value.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport sets the http.RoundTripper underneath the client, e.g. to
+// tune connection pooling. It takes precedence over the transport of a
+// client passed to WithHTTPClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithTimeout bounds the total time of every HTTP request made by the
+// client, including reading the response body.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithHeaders adds headers that are sent with every request. They are
+// applied after the default headers, so they may override them.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		for key, value := range headers {
+			c.headers[key] = value
+		}
+	}
+}
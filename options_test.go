@@ -0,0 +1,138 @@
+package chipotle_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+)
+
+// countingTransport counts the requests it sends with
+// http.DefaultTransport.
+type countingTransport struct {
+	mu       sync.Mutex
+	requests int
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests++
+	t.mu.Unlock()
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// Requests returns the number of requests sent so far.
+func (t *countingTransport) Requests() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.requests
+}
+
+func TestWithHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    map[string]string
+	}{
+		{
+			name: "defaults",
+			want: map[string]string{"Content-Type": "application/json", "Ocp-Apim-Subscription-Key": "test-key"},
+		},
+		{
+			name:    "added",
+			headers: map[string]string{"X-Trace-Id": "abc"},
+			want:    map[string]string{"Content-Type": "application/json", "X-Trace-Id": "abc"},
+		},
+		{
+			name:    "default overridden",
+			headers: map[string]string{"Content-Type": "application/problem+json"},
+			want:    map[string]string{"Content-Type": "application/problem+json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				w.Write([]byte(`{}`))
+			}))
+			defer s.Close()
+
+			c := chipotle.NewClient("test-key", chipotle.WithBaseURL(s.URL), chipotle.WithHeaders(tt.headers))
+			if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+				t.Fatalf("GetMenuContext() error = %v", err)
+			}
+
+			for key, value := range tt.want {
+				if got.Get(key) != value {
+					t.Errorf("header %s = %q, want %q", key, got.Get(key), value)
+				}
+			}
+		})
+	}
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	s := newServer(t, 1)
+	transport := &countingTransport{}
+	httpClient := &http.Client{Transport: transport, Timeout: time.Minute}
+
+	c := newClient(s, chipotle.WithHTTPClient(httpClient), chipotle.WithTimeout(time.Second))
+	if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+		t.Fatalf("GetMenuContext() error = %v", err)
+	}
+
+	// requests go through the caller's transport, which is left in place
+	if got := transport.Requests(); got != 1 {
+		t.Errorf("transport requests = %d, want 1", got)
+	}
+	if httpClient.Transport != transport || httpClient.Timeout != time.Minute {
+		t.Errorf("caller's client modified: %+v", httpClient)
+	}
+}
+
+func TestWithTransport(t *testing.T) {
+	tests := []struct {
+		name string
+		// transportFirst applies WithTransport before WithHTTPClient
+		transportFirst bool
+	}{
+		{name: "after WithHTTPClient"},
+		{name: "before WithHTTPClient", transportFirst: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 1)
+			clientTransport, transport := &countingTransport{}, &countingTransport{}
+
+			opts := []chipotle.Option{
+				chipotle.WithHTTPClient(&http.Client{Transport: clientTransport}),
+				chipotle.WithTransport(transport),
+			}
+			if tt.transportFirst {
+				opts[0], opts[1] = opts[1], opts[0]
+			}
+
+			c := newClient(s, opts...)
+			if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+				t.Fatalf("GetMenuContext() error = %v", err)
+			}
+
+			if got := transport.Requests(); got != 1 {
+				t.Errorf("WithTransport requests = %d, want 1", got)
+			}
+			if got := clientTransport.Requests(); got != 0 {
+				t.Errorf("WithHTTPClient transport requests = %d, want 0", got)
+			}
+		})
+	}
+}
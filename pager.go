@@ -0,0 +1,140 @@
+package chipotle
+
+import (
+	"context"
+
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+// SearchPager walks the pages of a search one request at a time, so
+// callers can process each page as it arrives, stop early, or resume a
+// search later from PageIndex.
+//
+//	pager := client.SearchPages(ctx, query)
+//	for pager.Next() {
+//		page := pager.Page()
+//		...
+//	}
+//	if err := pager.Err(); err != nil {
+//		// resume later with query.PageIndex = pager.PageIndex()
+//	}
+type SearchPager struct {
+	client *Client
+	ctx    context.Context
+	query  search.Query
+	page   *search.Result
+	raw    RawResponse
+	err    error
+	done   bool
+}
+
+// SearchPages returns a pager over the results of query, starting at
+// query.PageIndex.
+func (c *Client) SearchPages(ctx context.Context, query search.Query) *SearchPager {
+	return &SearchPager{
+		client: c,
+		ctx:    ctx,
+		query:  query,
+	}
+}
+
+// Next fetches the next page, reporting whether one was fetched. It
+// returns false once every page has been read or a request fails.
+func (p *SearchPager) Next() bool {
+	if p.done || p.err != nil {
+		return false
+	}
+
+	resp, err := p.client.SearchPageRaw(p.ctx, p.query)
+	if err != nil {
+		p.err = err
+		p.page = nil
+		p.raw = RawResponse{}
+		return false
+	}
+
+	page := resp.Result
+	p.page, p.raw = page, resp.Raw
+
+	// move on to the next page, unless this was the last one
+	next := page.PagingInfo.CurrentPage + 1
+	if page.PagingInfo.CurrentPage < page.PagingInfo.TotalPages && next > p.query.PageIndex {
+		p.query.PageIndex = next
+	} else {
+		p.done = true
+	}
+
+	return true
+}
+
+// Page returns the page fetched by the last call to Next.
+func (p *SearchPager) Page() *search.Result {
+	return p.page
+}
+
+// Raw returns the upstream payload of the page fetched by the last call to
+// Next.
+func (p *SearchPager) Raw() RawResponse {
+	return p.raw
+}
+
+// Err returns the error that stopped the pager, if any.
+func (p *SearchPager) Err() error {
+	return p.err
+}
+
+// PageIndex returns the index of the next page the pager will request.
+// After a failure it is the index of the page that failed, so setting
+// search.Query.PageIndex to it resumes the search where it stopped.
+func (p *SearchPager) PageIndex() int {
+	return p.query.PageIndex
+}
+
+// RestaurantIterator walks the restaurants matched by a search, fetching
+// pages on demand.
+type RestaurantIterator struct {
+	pager   *SearchPager
+	pending []restaurant.Restaurant
+	current restaurant.Restaurant
+}
+
+// SearchRestaurants returns an iterator over every restaurant matched by
+// query, starting at query.PageIndex.
+func (c *Client) SearchRestaurants(ctx context.Context, query search.Query) *RestaurantIterator {
+	return &RestaurantIterator{
+		pager: c.SearchPages(ctx, query),
+	}
+}
+
+// Next advances to the next restaurant, fetching another page if needed.
+// It returns false when the search is exhausted or a request fails.
+func (it *RestaurantIterator) Next() bool {
+	for len(it.pending) == 0 {
+		if !it.pager.Next() {
+			return false
+		}
+
+		it.pending = it.pager.Page().Restaurants
+	}
+
+	it.current, it.pending = it.pending[0], it.pending[1:]
+
+	return true
+}
+
+// Restaurant returns the restaurant at the iterator's current position.
+func (it *RestaurantIterator) Restaurant() restaurant.Restaurant {
+	return it.current
+}
+
+// Err returns the error that stopped the iterator, if any.
+func (it *RestaurantIterator) Err() error {
+	return it.pager.Err()
+}
+
+// PageIndex returns the index of the next page the iterator will request,
+// for resuming a search.
+func (it *RestaurantIterator) PageIndex() int {
+	return it.pager.PageIndex()
+}
@@ -0,0 +1,114 @@
+package chipotle_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+)
+
+func TestSearchPages(t *testing.T) {
+	tests := []struct {
+		name        string
+		restaurants int
+		pageSize    int
+		maxPageSize int
+		startPage   int
+		wantPages   int
+		wantFound   int
+	}{
+		{name: "one page", restaurants: 10, pageSize: 25, wantPages: 1, wantFound: 10},
+		{name: "several pages", restaurants: 60, pageSize: 25, wantPages: 3, wantFound: 60},
+		{name: "exact pages", restaurants: 50, pageSize: 25, wantPages: 2, wantFound: 50},
+		{name: "capped page size", restaurants: 60, pageSize: 100, maxPageSize: 20, wantPages: 3, wantFound: 60},
+		{name: "from a later page", restaurants: 60, pageSize: 25, startPage: 2, wantPages: 2, wantFound: 35},
+		{name: "no results", restaurants: 0, pageSize: 25, wantPages: 1, wantFound: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, tt.restaurants)
+			s.SetMaxPageSize(tt.maxPageSize)
+			c := newClient(s)
+
+			pager := c.SearchPages(context.Background(), nearby().PerPage(tt.pageSize).Page(tt.startPage))
+			pages, found := 0, map[int]bool{}
+			for pager.Next() {
+				pages++
+				for _, r := range pager.Page().Restaurants {
+					found[r.RestaurantNumber] = true
+				}
+			}
+			if err := pager.Err(); err != nil {
+				t.Fatalf("Err() = %v", err)
+			}
+
+			if pages != tt.wantPages {
+				t.Errorf("got %d pages, want %d", pages, tt.wantPages)
+			}
+			if len(found) != tt.wantFound {
+				t.Errorf("found %d restaurants, want %d", len(found), tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestSearchPagesResume(t *testing.T) {
+	tests := []struct {
+		name string
+		// failAt is the request that fails, counting from one
+		failAt        int
+		wantPageIndex int
+	}{
+		{name: "first page", failAt: 1, wantPageIndex: 0},
+		{name: "second page", failAt: 2, wantPageIndex: 2},
+		{name: "last page", failAt: 3, wantPageIndex: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 60)
+			c := newClient(s, chipotle.WithRetryPolicy(chipotle.RetryPolicy{}))
+			query := nearby().PerPage(25)
+
+			// let the pages before failAt through, then fail one request
+			found := map[int]bool{}
+			it := c.SearchRestaurants(context.Background(), query)
+			for i := 1; i < tt.failAt; i++ {
+				for j := 0; j < 25 && it.Next(); j++ {
+					found[it.Restaurant().RestaurantNumber] = true
+				}
+			}
+			s.InjectFault(chipotletest.Fault{Endpoint: chipotle.EndpointSearch, StatusCode: http.StatusInternalServerError, Times: 1})
+			for it.Next() {
+				found[it.Restaurant().RestaurantNumber] = true
+			}
+
+			if it.Err() == nil {
+				t.Fatal("Err() = nil, want the injected failure")
+			}
+			if got := it.PageIndex(); got != tt.wantPageIndex {
+				t.Fatalf("PageIndex() = %d, want %d", got, tt.wantPageIndex)
+			}
+
+			// resume where the search stopped
+			query.PageIndex = it.PageIndex()
+			it = c.SearchRestaurants(context.Background(), query)
+			for it.Next() {
+				found[it.Restaurant().RestaurantNumber] = true
+			}
+			if err := it.Err(); err != nil {
+				t.Fatalf("Err() after resuming = %v", err)
+			}
+
+			if len(found) != 60 {
+				t.Errorf("found %d restaurants, want 60", len(found))
+			}
+			if got, want := s.Requests(chipotle.EndpointSearch), 4; got != want {
+				t.Errorf("requests = %d, want %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package chipotle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that paces requests to a steady rate while
+// allowing short bursts. It is safe for concurrent use.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+	stats  RateLimiterStats
+}
+
+// RateLimiterStats is a snapshot of a RateLimiter's activity.
+type RateLimiterStats struct {
+	// Requests is the number of calls to Wait that acquired a token.
+	Requests int64
+	// Delayed is how many of those calls had to wait for a token.
+	Delayed int64
+	// Waiting is the number of callers blocked in Wait right now.
+	Waiting int
+	// TotalWait and MaxWait describe the time spent blocked in Wait.
+	TotalWait time.Duration
+	MaxWait   time.Duration
+	// Tokens is the number of tokens currently available.
+	Tokens float64
+}
+
+// NewRateLimiter returns a limiter allowing requestsPerSecond on average
+// and bursts of up to burst requests. A burst below one is treated as one,
+// and a non-positive rate never refills once the burst is spent.
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	burst = max(burst, 1)
+
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done. It returns ctx's
+// error if the wait was abandoned, in which case no token is consumed.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+
+	// reserve a token, going into debt if none are left
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 && l.rate <= 0 {
+		l.tokens++
+		l.mu.Unlock()
+		<-ctx.Done()
+		return ctx.Err()
+	} else if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		l.stats.Waiting++
+	}
+	l.mu.Unlock()
+
+	if delay > 0 {
+		err := sleep(ctx, delay)
+
+		l.mu.Lock()
+		l.stats.Waiting--
+		if err != nil {
+			// give the reservation back so later callers aren't delayed by it
+			l.tokens++
+			l.mu.Unlock()
+			return err
+		}
+		l.stats.Delayed++
+		l.stats.TotalWait += delay
+		l.stats.MaxWait = max(l.stats.MaxWait, delay)
+		l.mu.Unlock()
+	}
+
+	l.mu.Lock()
+	l.stats.Requests++
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Stats returns a snapshot of the limiter's activity.
+func (l *RateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	stats := l.stats
+	stats.Tokens = max(l.tokens, 0)
+
+	return stats
+}
+
+// refill adds the tokens accrued since the last refill. l.mu must be held.
+func (l *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+	l.tokens = min(l.tokens+elapsed*l.rate, float64(l.burst))
+}
+
+// WithRateLimit paces all requests made by the client to requestsPerSecond,
+// allowing bursts of up to burst requests.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(c *Client) {
+		c.rateLimiter = NewRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// WithEndpointRateLimit paces requests to a single endpoint, replacing the
+// client-wide limit for that endpoint.
+func WithEndpointRateLimit(endpoint Endpoint, requestsPerSecond float64, burst int) Option {
+	return func(c *Client) {
+		c.endpointRateLimiters[endpoint] = NewRateLimiter(requestsPerSecond, burst)
+	}
+}
+
+// RateLimiter returns the limiter that paces requests to endpoint, or nil
+// if requests to it are not rate limited.
+func (c *Client) RateLimiter(endpoint Endpoint) *RateLimiter {
+	if l, ok := c.endpointRateLimiters[endpoint]; ok {
+		return l
+	}
+
+	return c.rateLimiter
+}
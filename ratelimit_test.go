@@ -0,0 +1,103 @@
+package chipotle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+)
+
+func TestRateLimiter(t *testing.T) {
+	tests := []struct {
+		name        string
+		rate        float64
+		burst       int
+		calls       int
+		wantDelayed int64
+		minElapsed  time.Duration
+	}{
+		{name: "within burst", rate: 10, burst: 5, calls: 5},
+		{name: "beyond burst", rate: 100, burst: 2, calls: 5, wantDelayed: 3, minElapsed: 25 * time.Millisecond},
+		{name: "burst below one", rate: 100, burst: 0, calls: 3, wantDelayed: 2, minElapsed: 15 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := chipotle.NewRateLimiter(tt.rate, tt.burst)
+
+			start := time.Now()
+			for i := 0; i < tt.calls; i++ {
+				if err := l.Wait(context.Background()); err != nil {
+					t.Fatalf("Wait() error = %v", err)
+				}
+			}
+			elapsed := time.Since(start)
+
+			stats := l.Stats()
+			if stats.Requests != int64(tt.calls) {
+				t.Errorf("Requests = %d, want %d", stats.Requests, tt.calls)
+			}
+			if stats.Delayed != tt.wantDelayed {
+				t.Errorf("Delayed = %d, want %d", stats.Delayed, tt.wantDelayed)
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("took %v, want at least %v", elapsed, tt.minElapsed)
+			}
+		})
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := chipotle.NewRateLimiter(0, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	// a rate of zero never refills, so this waits for the context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if stats := l.Stats(); stats.Requests != 1 || stats.Waiting != 0 {
+		t.Errorf("Stats() = %+v, want 1 request and none waiting", stats)
+	}
+}
+
+func TestWithEndpointRateLimit(t *testing.T) {
+	s := newServer(t, 3)
+	c := newClient(s,
+		chipotle.WithRateLimit(1000, 10),
+		chipotle.WithEndpointRateLimit(chipotle.EndpointMenu, 100, 1),
+	)
+
+	for id := 1; id <= 3; id++ {
+		if _, err := c.GetMenuContext(context.Background(), id); err != nil {
+			t.Fatalf("GetMenuContext(%d) error = %v", id, err)
+		}
+	}
+	if _, err := c.SearchContext(context.Background(), nearby()); err != nil {
+		t.Fatalf("SearchContext() error = %v", err)
+	}
+
+	tests := []struct {
+		endpoint     chipotle.Endpoint
+		wantRequests int64
+		wantDelayed  int64
+	}{
+		{endpoint: chipotle.EndpointMenu, wantRequests: 3, wantDelayed: 2},
+		{endpoint: chipotle.EndpointSearch, wantRequests: 1, wantDelayed: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.endpoint), func(t *testing.T) {
+			stats := c.RateLimiter(tt.endpoint).Stats()
+			if stats.Requests != tt.wantRequests || stats.Delayed != tt.wantDelayed {
+				t.Errorf("Stats() = %+v, want %d requests, %d delayed", stats, tt.wantRequests, tt.wantDelayed)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package chipotle
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/kylegrantlucas/chipotle-go/menu"
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+// RawResponse is an upstream response exactly as it was received.
+type RawResponse struct {
+	// Body is the undecoded payload.
+	Body json.RawMessage
+	// Header holds the response headers. The cache stores only bodies, so
+	// it is empty for cached responses; use BypassCache to get headers.
+	Header http.Header
+	// Cached reports whether the response was served from the cache.
+	Cached bool
+}
+
+// SearchPageResponse is one page of search results with its raw payload.
+type SearchPageResponse struct {
+	Result *search.Result
+	Raw    RawResponse
+	// Drift lists how the payload differs from search.Result, if schema
+	// drift detection is enabled.
+	Drift []SchemaDrift
+}
+
+// MenuResponse is a menu with its raw payload.
+type MenuResponse struct {
+	Menu *menu.Menu
+	Raw  RawResponse
+	// Drift lists how the payload differs from menu.Menu, if schema drift
+	// detection is enabled.
+	Drift []SchemaDrift
+}
+
+func newRawResponse(resp *Response) RawResponse {
+	return RawResponse{
+		Body:   json.RawMessage(resp.Body),
+		Header: resp.Header,
+		Cached: resp.Cached,
+	}
+}
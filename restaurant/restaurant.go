@@ -11,7 +11,7 @@ type Restaurant struct {
 	OperationalSubRegion     string         `json:"operationalSubRegion,omitempty"`
 	OperationalPatch         string         `json:"operationalPatch,omitempty"`
 	DesignatedMarketAreaName string         `json:"designatedMarketAreaName,omitempty"`
-	Distance                 float64        `json:"distance,omitempty"`
+	Distance                 float64        `json:"distance,omitempty"` // see search.DistanceOf
 	Addresses                []Address      `json:"addresses,omitempty"`
 	Directions               Directions     `json:"directions,omitempty"`
 	Timezone                 Timezone       `json:"timezone,omitempty"`
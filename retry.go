@@ -0,0 +1,169 @@
+package chipotle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how the client retries failed requests. The zero
+// value performs a single attempt and never retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. Each following
+	// retry multiplies the delay by Multiplier, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+
+	// Jitter randomizes each delay by up to this fraction in either
+	// direction, e.g. 0.2 spreads a 1s delay over 0.8s to 1.2s.
+	Jitter float64
+
+	// RetryableStatusCodes lists the HTTP status codes worth retrying.
+	RetryableStatusCodes []int
+
+	// RetryableError reports whether a transport error is worth retrying.
+	// A nil func never retries transport errors.
+	RetryableError func(err error) bool
+
+	// MaxRetryAfter caps how long a Retry-After header on a 429 or 503 may
+	// delay the next attempt. Zero honors the header without a cap.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultRetryPolicy returns a policy suitable for long crawls: five
+// attempts with exponential backoff from 500ms to 30s, retrying throttling,
+// server errors and transient network failures.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableError: IsTemporaryNetworkError,
+		MaxRetryAfter:  2 * time.Minute,
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// IsTemporaryNetworkError reports whether err looks like a transient
+// network failure: a timeout, a reset or refused connection, or a
+// connection closed before the response arrived. Context cancellation is
+// never considered temporary.
+func IsTemporaryNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func (p RetryPolicy) attempts() int {
+	return max(p.MaxAttempts, 1)
+}
+
+func (p RetryPolicy) retryableStatus(code int) bool {
+	for _, c := range p.RetryableStatusCodes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p RetryPolicy) retryableError(err error) bool {
+	return p.RetryableError != nil && p.RetryableError(err)
+}
+
+// backoff returns the jittered delay before retry number n, starting at 1.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(n-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(delay)
+}
+
+// retryAfter returns the delay requested by a Retry-After header on a 429 or
+// 503 response, or zero if there is none.
+func (p RetryPolicy) retryAfter(resp *http.Response) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if t, err := http.ParseTime(value); err == nil {
+		delay = time.Until(t)
+	}
+
+	if delay < 0 {
+		return 0
+	}
+
+	if p.MaxRetryAfter > 0 && delay > p.MaxRetryAfter {
+		delay = p.MaxRetryAfter
+	}
+
+	return delay
+}
+
+// sleep waits for d or until ctx is done, whichever comes first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
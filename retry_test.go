@@ -0,0 +1,177 @@
+package chipotle_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		faults       []chipotletest.Fault
+		policy       chipotle.RetryPolicy
+		wantErr      error
+		wantRequests int
+		minElapsed   time.Duration
+	}{
+		{
+			name:         "success",
+			policy:       fastRetries(3),
+			wantRequests: 1,
+		},
+		{
+			name: "429 with Retry-After then success",
+			faults: []chipotletest.Fault{
+				{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second, Times: 1},
+			},
+			policy: func() chipotle.RetryPolicy {
+				// cap the header so the test stays fast, while still
+				// waiting far longer than the backoff would
+				p := fastRetries(3)
+				p.MaxRetryAfter = 100 * time.Millisecond
+				return p
+			}(),
+			wantRequests: 2,
+			minElapsed:   100 * time.Millisecond,
+		},
+		{
+			name: "server errors then success",
+			faults: []chipotletest.Fault{
+				{StatusCode: http.StatusInternalServerError, Times: 1},
+				{StatusCode: http.StatusBadGateway, Times: 1},
+			},
+			policy:       fastRetries(3),
+			wantRequests: 3,
+		},
+		{
+			name: "attempts exhausted",
+			faults: []chipotletest.Fault{
+				{StatusCode: http.StatusTooManyRequests},
+			},
+			policy:       fastRetries(3),
+			wantErr:      chipotle.ErrRateLimited,
+			wantRequests: 3,
+		},
+		{
+			name: "status not retryable",
+			faults: []chipotletest.Fault{
+				{StatusCode: http.StatusUnauthorized},
+			},
+			policy:       fastRetries(3),
+			wantErr:      chipotle.ErrUnauthorized,
+			wantRequests: 1,
+		},
+		{
+			name: "zero policy never retries",
+			faults: []chipotletest.Fault{
+				{StatusCode: http.StatusServiceUnavailable, Times: 1},
+			},
+			wantErr:      &chipotle.APIError{},
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 3)
+			for _, f := range tt.faults {
+				s.InjectFault(f)
+			}
+			c := newClient(s, chipotle.WithRetryPolicy(tt.policy))
+
+			start := time.Now()
+			_, err := c.GetMenuContext(context.Background(), 1)
+			elapsed := time.Since(start)
+
+			switch target := tt.wantErr.(type) {
+			case nil:
+				if err != nil {
+					t.Fatalf("GetMenuContext() error = %v", err)
+				}
+			case *chipotle.APIError:
+				if !errors.As(err, &target) {
+					t.Fatalf("GetMenuContext() error = %v, want an APIError", err)
+				}
+			default:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetMenuContext() error = %v, want %v", err, tt.wantErr)
+				}
+			}
+
+			if got := s.Requests(chipotle.EndpointMenu); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("took %v, want at least %v", elapsed, tt.minElapsed)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := chipotle.RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+
+	tests := []struct {
+		retry  int
+		jitter float64
+		min    time.Duration
+		max    time.Duration
+	}{
+		{retry: 1, min: 100 * time.Millisecond, max: 100 * time.Millisecond},
+		{retry: 2, min: 200 * time.Millisecond, max: 200 * time.Millisecond},
+		{retry: 4, min: 800 * time.Millisecond, max: 800 * time.Millisecond},
+		{retry: 5, min: time.Second, max: time.Second},
+		{retry: 10, min: time.Second, max: time.Second},
+		{retry: 1, jitter: 0.2, min: 80 * time.Millisecond, max: 120 * time.Millisecond},
+		{retry: 10, jitter: 0.2, min: 800 * time.Millisecond, max: 1200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("retry %d jitter %g", tt.retry, tt.jitter), func(t *testing.T) {
+			p := policy
+			p.Jitter = tt.jitter
+
+			for i := 0; i < 100; i++ {
+				if got := p.Backoff(tt.retry); got < tt.min || got > tt.max {
+					t.Fatalf("Backoff(%d) = %v, want between %v and %v", tt.retry, got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestIsTemporaryNetworkError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: io.EOF, want: true},
+		{err: io.ErrUnexpectedEOF, want: true},
+		{err: fmt.Errorf("failed to read: %w", syscall.ECONNRESET), want: true},
+		{err: syscall.ECONNREFUSED, want: true},
+		{err: context.Canceled, want: false},
+		{err: context.DeadlineExceeded, want: false},
+		{err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := chipotle.IsTemporaryNetworkError(tt.err); got != tt.want {
+				t.Errorf("IsTemporaryNetworkError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package search
+
+import "math"
+
+// Status is the status of a restaurant, as matched by
+// Query.RestaurantStatuses.
+type Status string
+
+// Restaurant statuses.
+const (
+	StatusOpen Status = "OPEN"
+	// StatusLab marks test kitchens that are open to the public.
+	StatusLab Status = "LAB"
+)
+
+// ConceptID identifies a restaurant brand, as matched by Query.ConceptIds.
+type ConceptID string
+
+// ConceptChipotle is Chipotle Mexican Grill.
+const ConceptChipotle ConceptID = "CMG"
+
+// OrderField is a field results can be ordered by with Query.OrderBy.
+type OrderField string
+
+// OrderFieldDistance orders results by their distance from the query's
+// coordinates.
+const OrderFieldDistance OrderField = "distance"
+
+// AddressType is the kind of an address, as selected by
+// Embeds.AddressTypes.
+type AddressType string
+
+// AddressMain is a restaurant's street address.
+const AddressMain AddressType = "MAIN"
+
+// NewQuery returns a query for Chipotle restaurants, to be refined with the
+// builder methods:
+//
+//	query := search.NewQuery().
+//		Near(38.678, -121.176).
+//		WithinMiles(25).
+//		Statuses(search.StatusOpen).
+//		EmbedAll().
+//		OrderByDistance()
+//
+// Every builder method returns a modified copy, so a query can be used as
+// the base of several others.
+func NewQuery() Query {
+	return Query{
+		ConceptIds: []string{string(ConceptChipotle)},
+	}
+}
+
+// Near centers the search on the given coordinates.
+func (q Query) Near(latitude, longitude float64) Query {
+	q.Latitude, q.Longitude = latitude, longitude
+	return q
+}
+
+// Within limits the search to restaurants within radius of the query's
+// coordinates, converted to RadiusUnit and rounded.
+func (q Query) Within(radius Distance) Query {
+	q.Radius = int(math.Round(float64(radius / RadiusUnit)))
+	return q
+}
+
+// WithinMeters is Within(Meters(meters)).
+func (q Query) WithinMeters(meters int) Query {
+	return q.Within(Meters(float64(meters)))
+}
+
+// WithinKilometers is Within(Kilometers(km)).
+func (q Query) WithinKilometers(km float64) Query {
+	return q.Within(Kilometers(km))
+}
+
+// WithinMiles is Within(Miles(miles)).
+func (q Query) WithinMiles(miles float64) Query {
+	return q.Within(Miles(miles))
+}
+
+// Statuses limits the search to restaurants with one of statuses.
+func (q Query) Statuses(statuses ...Status) Query {
+	q.RestaurantStatuses = make([]string, len(statuses))
+	for i, s := range statuses {
+		q.RestaurantStatuses[i] = string(s)
+	}
+	return q
+}
+
+// Concepts limits the search to restaurants of one of concepts.
+func (q Query) Concepts(concepts ...ConceptID) Query {
+	q.ConceptIds = make([]string, len(concepts))
+	for i, c := range concepts {
+		q.ConceptIds[i] = string(c)
+	}
+	return q
+}
+
+// SortBy orders the results by field, ascending.
+func (q Query) SortBy(field OrderField) Query {
+	q.OrderBy, q.OrderByDescending = string(field), false
+	return q
+}
+
+// OrderByDistance orders the results nearest first.
+func (q Query) OrderByDistance() Query {
+	return q.SortBy(OrderFieldDistance)
+}
+
+// Descending reverses the order of the results.
+func (q Query) Descending() Query {
+	q.OrderByDescending = true
+	return q
+}
+
+// PerPage sets the number of restaurants returned per page.
+func (q Query) PerPage(size int) Query {
+	q.PageSize = size
+	return q
+}
+
+// Page sets the index of the page to fetch first.
+func (q Query) Page(index int) Query {
+	q.PageIndex = index
+	return q
+}
+
+// AddressTypes selects the kinds of addresses returned with each
+// restaurant.
+func (q Query) AddressTypes(types ...AddressType) Query {
+	q.Embeds.AddressTypes = make([]string, len(types))
+	for i, t := range types {
+		q.Embeds.AddressTypes[i] = string(t)
+	}
+	return q
+}
+
+// EmbedAll returns every section of each restaurant, with its main address
+// unless address types were already selected.
+func (q Query) EmbedAll() Query {
+	addressTypes := q.Embeds.AddressTypes
+	if len(addressTypes) == 0 {
+		addressTypes = []string{string(AddressMain)}
+	}
+
+	q.Embeds = Embeds{
+		AddressTypes:   addressTypes,
+		RealHours:      true,
+		Directions:     true,
+		Catering:       true,
+		OnlineOrdering: true,
+		Timezone:       true,
+		Marketing:      true,
+		Chipotlane:     true,
+		Sustainability: true,
+		Experience:     true,
+	}
+	return q
+}
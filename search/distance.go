@@ -0,0 +1,99 @@
+package search
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+)
+
+// Distance is a length, stored in meters.
+type Distance float64
+
+// Common distances, for writing e.g. 25 * search.Mile.
+const (
+	Meter     Distance = 1
+	Kilometer Distance = 1000
+	Mile      Distance = 1609.344
+)
+
+// The units the API is assumed to use. The API doesn't document them and
+// they haven't been confirmed against a recorded live response: meters fits
+// the radius the crawler has always used, and miles fits the distances it
+// stored. Query.Within, Query.RadiusDistance and DistanceOf convert through
+// these, so a correction only needs to change them.
+const (
+	// RadiusUnit is the unit of Query.Radius. Unverified.
+	RadiusUnit = Meter
+	// ResultDistanceUnit is the unit of restaurant.Restaurant.Distance.
+	// Unverified.
+	ResultDistanceUnit = Mile
+)
+
+// Meters returns a Distance of m meters.
+func Meters(m float64) Distance {
+	return Distance(m)
+}
+
+// Kilometers returns a Distance of km kilometers.
+func Kilometers(km float64) Distance {
+	return Distance(km) * Kilometer
+}
+
+// Miles returns a Distance of mi miles.
+func Miles(mi float64) Distance {
+	return Distance(mi) * Mile
+}
+
+// Meters returns d in meters.
+func (d Distance) Meters() float64 {
+	return float64(d)
+}
+
+// Kilometers returns d in kilometers.
+func (d Distance) Kilometers() float64 {
+	return float64(d / Kilometer)
+}
+
+// Miles returns d in miles.
+func (d Distance) Miles() float64 {
+	return float64(d / Mile)
+}
+
+// String formats d in kilometers, or meters below one kilometer.
+func (d Distance) String() string {
+	if math.Abs(float64(d)) < float64(Kilometer) {
+		return fmt.Sprintf("%gm", math.Round(d.Meters()))
+	}
+
+	return fmt.Sprintf("%.1fkm", d.Kilometers())
+}
+
+// earthRadius is the mean radius of the Earth.
+const earthRadius = 6371 * Kilometer
+
+// DistanceBetween returns the great-circle distance between two points.
+func DistanceBetween(lat1, lng1, lat2, lng2 float64) Distance {
+	dLat := radians(lat2 - lat1)
+	dLng := radians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadius * Distance(math.Asin(math.Sqrt(a)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// RadiusDistance returns q.Radius as a Distance, assuming it is in
+// RadiusUnit.
+func (q Query) RadiusDistance() Distance {
+	return Distance(q.Radius) * RadiusUnit
+}
+
+// DistanceOf returns how far r is from the coordinates of the query that
+// found it, assuming the API reports it in ResultDistanceUnit.
+func DistanceOf(r restaurant.Restaurant) Distance {
+	return Distance(r.Distance) * ResultDistanceUnit
+}
@@ -0,0 +1,93 @@
+package search_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name     string
+		distance search.Distance
+		want     float64
+	}{
+		{name: "miles to meters", distance: search.Miles(1), want: 1609.344},
+		{name: "kilometers to meters", distance: search.Kilometers(2.5), want: 2500},
+		{name: "meters", distance: search.Meters(800), want: 800},
+		{name: "one degree of latitude", distance: search.DistanceBetween(38, -121, 39, -121), want: 111195},
+		{name: "same point", distance: search.DistanceBetween(38.67, -121.17, 38.67, -121.17), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.distance.Meters(); math.Abs(got-tt.want) > 1 {
+				t.Errorf("Meters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got := search.Kilometers(1).Miles(); math.Abs(got-0.621371) > 1e-6 {
+		t.Errorf("Kilometers(1).Miles() = %v, want 0.621371", got)
+	}
+	if got, want := search.Meters(800).String(), "800m"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := search.Miles(1).String(), "1.6km"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestDistanceRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		radius search.Distance
+		query  search.Query
+	}{
+		{name: "Within", radius: 5 * search.Mile, query: search.NewQuery().Within(5 * search.Mile)},
+		{name: "WithinMiles", radius: 5 * search.Mile, query: search.NewQuery().WithinMiles(5)},
+		{name: "WithinKilometers", radius: 12.5 * search.Kilometer, query: search.NewQuery().WithinKilometers(12.5)},
+		{name: "WithinMeters", radius: 800 * search.Meter, query: search.NewQuery().WithinMeters(800)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the radius is sent rounded to a whole RadiusUnit
+			if got := tt.query.RadiusDistance(); math.Abs(float64(got-tt.radius)) > float64(search.RadiusUnit)/2 {
+				t.Errorf("RadiusDistance() = %v, want %v", got, tt.radius)
+			}
+		})
+	}
+
+	// a restaurant one kilometer north of the query, found and measured
+	// through the fake server
+	s := chipotletest.NewServer()
+	defer s.Close()
+	s.AddRestaurants(restaurant.Restaurant{
+		RestaurantNumber: 1,
+		Addresses:        []restaurant.Address{{AddressType: string(search.AddressMain), Latitude: 38.67 + 1/111.195, Longitude: -121.17}},
+	})
+	c := chipotle.NewClient("test-key", chipotle.WithBaseURL(s.URL))
+
+	for _, radius := range []search.Distance{search.Miles(1), 999 * search.Meter} {
+		result, err := c.SearchPage(context.Background(), search.NewQuery().Near(38.67, -121.17).Within(radius))
+		if err != nil {
+			t.Fatalf("SearchPage() error = %v", err)
+		}
+
+		wantFound := radius > search.Kilometer
+		if got := len(result.Restaurants) == 1; got != wantFound {
+			t.Fatalf("within %v: found = %v, want %v", radius, got, wantFound)
+		}
+		if wantFound {
+			if got := search.DistanceOf(result.Restaurants[0]); math.Abs(got.Meters()-1000) > 1 {
+				t.Errorf("DistanceOf() = %v, want 1km", got)
+			}
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package search
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+)
+
+// Filter is a predicate on restaurants, for narrowing results on the
+// client. Filters on a section of the restaurant only match if the search
+// embedded that section, e.g. HasChipotlane needs Embeds.Chipotlane.
+//
+//	drive := search.And(search.HasChipotlane(), search.InState("CA"), search.OpenAt(time.Now()))
+//	for _, r := range drive.Apply(result) {
+//		...
+//	}
+type Filter func(r restaurant.Restaurant) bool
+
+// Match reports whether r passes the filter.
+func (f Filter) Match(r restaurant.Restaurant) bool {
+	return f(r)
+}
+
+// Restaurants returns the restaurants that pass the filter, in order.
+func (f Filter) Restaurants(restaurants []restaurant.Restaurant) []restaurant.Restaurant {
+	var matches []restaurant.Restaurant
+	for _, r := range restaurants {
+		if f(r) {
+			matches = append(matches, r)
+		}
+	}
+
+	return matches
+}
+
+// Apply returns the restaurants of result that pass the filter.
+func (f Filter) Apply(result *Result) []restaurant.Restaurant {
+	if result == nil {
+		return nil
+	}
+
+	return f.Restaurants(result.Restaurants)
+}
+
+// Iterator is a stream of restaurants, such as the one returned by
+// chipotle.Client.SearchRestaurants.
+type Iterator interface {
+	Next() bool
+	Restaurant() restaurant.Restaurant
+	Err() error
+}
+
+// Iterate returns an iterator over the restaurants of it that pass the
+// filter.
+func (f Filter) Iterate(it Iterator) Iterator {
+	return &filterIterator{it: it, filter: f}
+}
+
+type filterIterator struct {
+	it     Iterator
+	filter Filter
+}
+
+func (fi *filterIterator) Next() bool {
+	for fi.it.Next() {
+		if fi.filter(fi.it.Restaurant()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (fi *filterIterator) Restaurant() restaurant.Restaurant {
+	return fi.it.Restaurant()
+}
+
+func (fi *filterIterator) Err() error {
+	return fi.it.Err()
+}
+
+// And matches restaurants that pass every filter.
+func And(filters ...Filter) Filter {
+	return func(r restaurant.Restaurant) bool {
+		for _, f := range filters {
+			if !f(r) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or matches restaurants that pass any filter.
+func Or(filters ...Filter) Filter {
+	return func(r restaurant.Restaurant) bool {
+		for _, f := range filters {
+			if f(r) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not matches restaurants that don't pass f.
+func Not(f Filter) Filter {
+	return func(r restaurant.Restaurant) bool {
+		return !f(r)
+	}
+}
+
+// HasChipotlane matches restaurants with a Chipotlane drive-thru pickup
+// lane.
+func HasChipotlane() Filter {
+	return func(r restaurant.Restaurant) bool {
+		return r.Chipotlane.ChipotlanePickupEnabled
+	}
+}
+
+// CateringEnabled matches restaurants that take catering orders.
+func CateringEnabled() Filter {
+	return func(r restaurant.Restaurant) bool {
+		return r.Catering.CateringEnabled
+	}
+}
+
+// CurbsidePickupEnabled matches restaurants with curbside pickup.
+func CurbsidePickupEnabled() Filter {
+	return func(r restaurant.Restaurant) bool {
+		return r.Experience.CurbsidePickupEnabled
+	}
+}
+
+// OnlineOrderingEnabled matches restaurants that take online orders.
+func OnlineOrderingEnabled() Filter {
+	return func(r restaurant.Restaurant) bool {
+		return r.OnlineOrdering.OnlineOrderingEnabled
+	}
+}
+
+// InState matches restaurants with an address in one of states, given as
+// the abbreviations the API uses, such as "CA". Provinces work too.
+func InState(states ...string) Filter {
+	return func(r restaurant.Restaurant) bool {
+		for _, a := range r.Addresses {
+			if containsFold(states, a.AdministrativeArea) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// InCountry matches restaurants with an address in one of countries,
+// given as country codes such as "US".
+func InCountry(countries ...string) Filter {
+	return func(r restaurant.Restaurant) bool {
+		for _, a := range r.Addresses {
+			if containsFold(countries, a.CountryCode) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// LocationType matches restaurants of one of types, compared to
+// RestaurantLocationType ignoring case.
+func LocationType(types ...string) Filter {
+	return func(r restaurant.Restaurant) bool {
+		return containsFold(types, r.RestaurantLocationType)
+	}
+}
+
+// OpenAt matches restaurants whose RealHours cover t, which needs
+// Embeds.RealHours. Hours are read in the restaurant's time zone when
+// Embeds.Timezone names one, and in t's location otherwise.
+func OpenAt(t time.Time) Filter {
+	return func(r restaurant.Restaurant) bool {
+		local := t.In(restaurantLocation(r, t.Location()))
+		for _, h := range r.RealHours {
+			if openDuring(h, local) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+var (
+	dateTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05"}
+	clockLayouts    = []string{"15:04:05", "15:04", "3:04 PM", "3:04PM"}
+)
+
+// openDuring reports whether t, in the restaurant's time zone, falls in h.
+// Hours are either full timestamps, or times of day on h.DayOfWeek.
+// Closing times before the opening time are on the next day.
+func openDuring(h restaurant.RealHours, t time.Time) bool {
+	if opens, ok := parseTime(dateTimeLayouts, h.OpenDateTime, t.Location()); ok {
+		closes, ok := parseTime(dateTimeLayouts, h.CloseDateTime, t.Location())
+		if !ok {
+			return false
+		}
+		if !closes.After(opens) {
+			closes = closes.AddDate(0, 0, 1)
+		}
+		return !t.Before(opens) && t.Before(closes)
+	}
+
+	opens, ok := parseTime(clockLayouts, h.OpenDateTime, time.UTC)
+	if !ok {
+		return false
+	}
+	closes, ok := parseTime(clockLayouts, h.CloseDateTime, time.UTC)
+	if !ok {
+		return false
+	}
+
+	// check the hours starting today and, for hours past midnight,
+	// yesterday
+	for _, day := range []time.Time{t, t.AddDate(0, 0, -1)} {
+		if h.DayOfWeek != "" && !sameWeekday(h.DayOfWeek, day.Weekday()) {
+			continue
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), opens.Hour(), opens.Minute(), opens.Second(), 0, t.Location())
+		end := time.Date(day.Year(), day.Month(), day.Day(), closes.Hour(), closes.Minute(), closes.Second(), 0, t.Location())
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseTime(layouts []string, value string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), loc); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// sameWeekday reports whether name, such as "Monday", "MON" or "mon", is
+// day.
+func sameWeekday(name string, day time.Weekday) bool {
+	name = strings.TrimSpace(name)
+	return len(name) >= 3 && strings.HasPrefix(strings.ToLower(day.String()), strings.ToLower(name))
+}
+
+var locations sync.Map // time zone name → *time.Location, or nil if unknown
+
+// restaurantLocation returns the time zone of r, or fallback if it is
+// unknown.
+func restaurantLocation(r restaurant.Restaurant, fallback *time.Location) *time.Location {
+	for _, name := range []string{r.Timezone.TimezoneID, r.Timezone.Timezone} {
+		if name == "" {
+			continue
+		}
+
+		cached, ok := locations.Load(name)
+		if !ok {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				loc = nil
+			}
+			cached, _ = locations.LoadOrStore(name, loc)
+		}
+		if loc := cached.(*time.Location); loc != nil {
+			return loc
+		}
+	}
+
+	return fallback
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,130 @@
+package search_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+func TestOpenAt(t *testing.T) {
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("no time zone database: %v", err)
+	}
+
+	// Monday, October 19 2026
+	monday := func(hour, min int, loc *time.Location) time.Time {
+		return time.Date(2026, time.October, 19, hour, min, 0, 0, loc)
+	}
+	hours := func(day, opens, closes string) []restaurant.RealHours {
+		return []restaurant.RealHours{{DayOfWeek: day, OpenDateTime: opens, CloseDateTime: closes}}
+	}
+
+	tests := []struct {
+		name     string
+		hours    []restaurant.RealHours
+		timezone string
+		at       time.Time
+		want     bool
+	}{
+		{name: "during hours", hours: hours("Monday", "10:45", "22:00"), at: monday(12, 0, time.UTC), want: true},
+		{name: "at opening", hours: hours("Monday", "10:45", "22:00"), at: monday(10, 45, time.UTC), want: true},
+		{name: "at closing", hours: hours("Monday", "10:45", "22:00"), at: monday(22, 0, time.UTC), want: false},
+		{name: "before opening", hours: hours("Monday", "10:45", "22:00"), at: monday(9, 0, time.UTC), want: false},
+		{name: "other day", hours: hours("Tuesday", "10:45", "22:00"), at: monday(12, 0, time.UTC), want: false},
+		{name: "abbreviated day", hours: hours("MON", "10:45", "22:00"), at: monday(12, 0, time.UTC), want: true},
+		{name: "any day", hours: hours("", "10:45", "22:00"), at: monday(12, 0, time.UTC), want: true},
+		{name: "12-hour clock", hours: hours("Monday", "10:45 AM", "10:00 PM"), at: monday(21, 0, time.UTC), want: true},
+		{name: "with seconds", hours: hours("Monday", "10:45:00", "22:00:00"), at: monday(12, 0, time.UTC), want: true},
+		{name: "past midnight", hours: hours("Sunday", "18:00", "02:00"), at: monday(1, 0, time.UTC), want: true},
+		{name: "past midnight ended", hours: hours("Sunday", "18:00", "02:00"), at: monday(3, 0, time.UTC), want: false},
+		{
+			name:  "timestamps",
+			hours: hours("", "2026-10-19T10:45:00", "2026-10-19T22:00:00"),
+			at:    monday(12, 0, time.UTC),
+			want:  true,
+		},
+		{
+			name:  "timestamps on another day",
+			hours: hours("", "2026-10-20T10:45:00", "2026-10-20T22:00:00"),
+			at:    monday(12, 0, time.UTC),
+			want:  false,
+		},
+		{
+			name: "several ranges",
+			hours: []restaurant.RealHours{
+				{DayOfWeek: "Monday", OpenDateTime: "07:00", CloseDateTime: "10:00"},
+				{DayOfWeek: "Monday", OpenDateTime: "11:00", CloseDateTime: "14:00"},
+			},
+			at:   monday(12, 0, time.UTC),
+			want: true,
+		},
+		// 03:00 UTC on Monday is 20:00 on Sunday in Los Angeles
+		{name: "restaurant time zone", hours: hours("Sunday", "10:45", "22:00"), timezone: "America/Los_Angeles", at: monday(3, 0, time.UTC), want: true},
+		{name: "time zone of t", hours: hours("Sunday", "10:45", "22:00"), at: monday(3, 0, time.UTC), want: false},
+		{name: "unknown time zone", hours: hours("Sunday", "10:45", "22:00"), timezone: "Mars/Olympus", at: monday(3, 0, time.UTC).In(losAngeles), want: true},
+		{name: "invalid hours", hours: hours("Monday", "noon", "22:00"), at: monday(12, 0, time.UTC), want: false},
+		{name: "no hours", at: monday(12, 0, time.UTC), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := restaurant.Restaurant{
+				RealHours: tt.hours,
+				Timezone:  restaurant.Timezone{TimezoneID: tt.timezone},
+			}
+
+			if got := search.OpenAt(tt.at).Match(r); got != tt.want {
+				t.Errorf("OpenAt(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterCombinators(t *testing.T) {
+	drive := restaurant.Restaurant{
+		RestaurantNumber: 1,
+		Chipotlane:       restaurant.Chipotlane{ChipotlanePickupEnabled: true},
+		Addresses:        []restaurant.Address{{AdministrativeArea: "CA", CountryCode: "US"}},
+	}
+	catering := restaurant.Restaurant{
+		RestaurantNumber: 2,
+		Catering:         restaurant.Catering{CateringEnabled: true},
+		Addresses:        []restaurant.Address{{AdministrativeArea: "ON", CountryCode: "CA"}},
+	}
+	restaurants := []restaurant.Restaurant{drive, catering}
+
+	tests := []struct {
+		name   string
+		filter search.Filter
+		want   []int
+	}{
+		{name: "chipotlane", filter: search.HasChipotlane(), want: []int{1}},
+		{name: "state ignores case", filter: search.InState("ca"), want: []int{1}},
+		{name: "country", filter: search.InCountry("CA"), want: []int{2}},
+		{name: "and", filter: search.And(search.InCountry("US"), search.CateringEnabled()), want: nil},
+		{name: "or", filter: search.Or(search.HasChipotlane(), search.CateringEnabled()), want: []int{1, 2}},
+		{name: "not", filter: search.Not(search.HasChipotlane()), want: []int{2}},
+		{name: "empty and", filter: search.And(), want: []int{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, r := range tt.filter.Restaurants(restaurants) {
+				got = append(got, r.RestaurantNumber)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("matched %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("matched %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
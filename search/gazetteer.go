@@ -0,0 +1,383 @@
+package search
+
+import (
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The embedded tables, which may be gzipped. cities.csv lists the major
+// cities, under their English and native names; towns.csv.gz lists every
+// place of more than 1000 people in GeoNames (CC BY 4.0, geonames.org).
+//
+//go:generate go run gen_gazetteer.go
+//go:embed gazetteer
+var gazetteerData embed.FS
+
+var (
+	// ErrUnknownPlace is returned for postal codes and cities missing from
+	// the gazetteer.
+	ErrUnknownPlace = errors.New("unknown place")
+	// ErrAmbiguousPlace is returned by City when a city without a region
+	// matches more than one place.
+	ErrAmbiguousPlace = errors.New("ambiguous place")
+)
+
+// Place is a named location in a Gazetteer.
+type Place struct {
+	Name string
+	// Region is the state or province in the US and Canada, and the
+	// country code elsewhere.
+	Region    string
+	Country   string
+	Latitude  float64
+	Longitude float64
+}
+
+// Gazetteer resolves postal codes and city names to coordinates offline.
+// It is safe for concurrent use.
+type Gazetteer struct {
+	mu          sync.RWMutex
+	postalCodes map[string]Place
+	cities      map[string][]Place
+	// towns are only looked at when no city matches, so that e.g. Paris
+	// means the one in France unless a region says otherwise.
+	towns map[string][]Place
+}
+
+// NewGazetteer returns an empty gazetteer.
+func NewGazetteer() *Gazetteer {
+	return &Gazetteer{
+		postalCodes: map[string]Place{},
+		cities:      map[string][]Place{},
+		towns:       map[string][]Place{},
+	}
+}
+
+var defaultGazetteer = sync.OnceValue(func() *Gazetteer {
+	g := NewGazetteer()
+	loaders := map[string]func(io.Reader) error{
+		"cities":       g.LoadCities,
+		"towns":        g.loadTowns,
+		"postal_codes": g.LoadPostalCodes,
+	}
+
+	files, err := fs.ReadDir(gazetteerData, "gazetteer")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range files {
+		table, _, _ := strings.Cut(file.Name(), ".")
+		load, ok := loaders[table]
+		if !ok {
+			continue
+		}
+
+		data, err := gazetteerData.ReadFile("gazetteer/" + file.Name())
+		if err != nil {
+			panic(err)
+		}
+		if err := load(bytes.NewReader(data)); err != nil {
+			panic(fmt.Sprintf("failed to load %s: %v", file.Name(), err))
+		}
+	}
+
+	return g
+})
+
+// DefaultGazetteer returns the gazetteer used by NearPostalCode and
+// NearCity. It ships with every town of more than 1000 people in the US,
+// Canada, the UK, France and Germany, but only a sample of US ZIP codes
+// unless gen_gazetteer.go was run; load a complete list into it with
+// LoadPostalCodes.
+func DefaultGazetteer() *Gazetteer {
+	return defaultGazetteer()
+}
+
+// NearPostalCode returns a query for restaurants within radius of the
+// center of a postal code in the default gazetteer.
+func NearPostalCode(code string, radius Distance) (Query, error) {
+	return DefaultGazetteer().NearPostalCode(code, radius)
+}
+
+// NearCity returns a query for restaurants within radius of the center of
+// a city in the default gazetteer. See Gazetteer.City.
+func NearCity(city, region string, radius Distance) (Query, error) {
+	return DefaultGazetteer().NearCity(city, region, radius)
+}
+
+// NearPostalCode is like the package-level NearPostalCode, using g.
+func (g *Gazetteer) NearPostalCode(code string, radius Distance) (Query, error) {
+	place, err := g.PostalCode(code)
+	if err != nil {
+		return Query{}, err
+	}
+
+	return NewQuery().Near(place.Latitude, place.Longitude).Within(radius), nil
+}
+
+// NearCity is like the package-level NearCity, using g.
+func (g *Gazetteer) NearCity(city, region string, radius Distance) (Query, error) {
+	place, err := g.City(city, region)
+	if err != nil {
+		return Query{}, err
+	}
+
+	return NewQuery().Near(place.Latitude, place.Longitude).Within(radius), nil
+}
+
+// PostalCode looks up a postal code. ZIP+4 codes are looked up by their
+// first five digits, and codes with a space, such as UK postcodes, fall
+// back to the part before it.
+func (g *Gazetteer) PostalCode(code string) (Place, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	code = strings.ToUpper(strings.TrimSpace(code))
+	candidates := []string{code}
+	if zip, _, ok := strings.Cut(code, "-"); ok {
+		candidates = append(candidates, zip)
+	}
+	if outward, _, ok := strings.Cut(code, " "); ok {
+		candidates = append(candidates, outward)
+	}
+
+	for _, c := range candidates {
+		if place, ok := g.postalCodes[c]; ok {
+			return place, nil
+		}
+	}
+
+	return Place{}, fmt.Errorf("%w: postal code %q", ErrUnknownPlace, code)
+}
+
+// City looks up a city by name and region, ignoring case and accents.
+// region is a US state or Canadian province abbreviation, or a country
+// code such as GB, FR or DE. An empty region matches any, as long as only
+// one city has the name. Major cities win over smaller towns of the same
+// name.
+func (g *Gazetteer) City(name, region string) (Place, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	key := foldName(name)
+	region = strings.ToUpper(strings.TrimSpace(region))
+	if region == "UK" {
+		region = "GB"
+	}
+
+	place, err := matchPlace(g.cities[key], name, region)
+	if errors.Is(err, ErrUnknownPlace) {
+		return matchPlace(g.towns[key], name, region)
+	}
+
+	return place, err
+}
+
+// matchPlace picks the place in region out of places, all named name.
+func matchPlace(places []Place, name, region string) (Place, error) {
+	if region == "" {
+		switch len(places) {
+		case 0:
+		case 1:
+			return places[0], nil
+		default:
+			return Place{}, fmt.Errorf("%w: %d cities named %q, give a region", ErrAmbiguousPlace, len(places), name)
+		}
+	}
+
+	// prefer an exact region, then fall back to the country
+	for _, p := range places {
+		if p.Region == region {
+			return p, nil
+		}
+	}
+	for _, p := range places {
+		if p.Country == region {
+			return p, nil
+		}
+	}
+
+	if region == "" {
+		return Place{}, fmt.Errorf("%w: city %q", ErrUnknownPlace, name)
+	}
+
+	return Place{}, fmt.Errorf("%w: city %q in %s", ErrUnknownPlace, name, region)
+}
+
+// LoadCities adds the cities in r, a CSV file with a header and the
+// columns name, region, country, latitude and longitude. r may be gzipped.
+func (g *Gazetteer) LoadCities(r io.Reader) error {
+	return g.loadPlaces(r, g.cities)
+}
+
+// loadTowns is like LoadCities, adding places that are only looked at when
+// no city matches.
+func (g *Gazetteer) loadTowns(r io.Reader) error {
+	return g.loadPlaces(r, g.towns)
+}
+
+func (g *Gazetteer) loadPlaces(r io.Reader, into map[string][]Place) error {
+	rows, err := readTable(r, map[string][]string{
+		"name":      {"name"},
+		"region":    {"region"},
+		"country":   {"country"},
+		"latitude":  {"latitude"},
+		"longitude": {"longitude"},
+	})
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, row := range rows {
+		place := Place{
+			Name:      row.values["name"],
+			Region:    strings.ToUpper(row.values["region"]),
+			Country:   strings.ToUpper(row.values["country"]),
+			Latitude:  row.latitude,
+			Longitude: row.longitude,
+		}
+
+		key := foldName(place.Name)
+		into[key] = append(into[key], place)
+	}
+
+	return nil
+}
+
+// LoadPostalCodes adds the postal codes in r, a comma or tab separated
+// file with a header naming a code, latitude and longitude column. Besides
+// plain code,latitude,longitude files, this reads the Census Bureau's ZCTA
+// gazetteer files, which cover every US ZIP code. r may be gzipped.
+func (g *Gazetteer) LoadPostalCodes(r io.Reader) error {
+	rows, err := readTable(r, map[string][]string{
+		"code":      {"code", "postal_code", "zip", "geoid"},
+		"latitude":  {"latitude", "lat", "intptlat"},
+		"longitude": {"longitude", "lng", "lon", "intptlong"},
+	})
+	if err != nil {
+		return err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, row := range rows {
+		code := strings.ToUpper(row.values["code"])
+		g.postalCodes[code] = Place{
+			Name:      code,
+			Latitude:  row.latitude,
+			Longitude: row.longitude,
+		}
+	}
+
+	return nil
+}
+
+type tableRow struct {
+	values              map[string]string
+	latitude, longitude float64
+}
+
+// readTable reads a comma or tab separated file, gzipped or not, picking
+// each column of columns by the first of its aliases found in the header.
+// Every column must be present, and latitude and longitude must be numbers.
+func readTable(r io.Reader, columns map[string][]string) ([]tableRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table: %w", err)
+	}
+
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress table: %w", err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("failed to decompress table: %w", err)
+		}
+	}
+
+	// sniff the separator from the header
+	header, _, _ := bytes.Cut(data, []byte("\n"))
+
+	cr := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(header, []byte("\t")) > bytes.Count(header, []byte(",")) {
+		cr.Comma = '\t'
+	}
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read table: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing header")
+	}
+
+	index := map[string]int{}
+	for i, name := range records[0] {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	positions := map[string]int{}
+	for column, aliases := range columns {
+		for _, alias := range aliases {
+			if i, ok := index[alias]; ok {
+				positions[column] = i
+				break
+			}
+		}
+		if _, ok := positions[column]; !ok {
+			return nil, fmt.Errorf("missing %s column", column)
+		}
+	}
+
+	rows := make([]tableRow, 0, len(records)-1)
+	for n, record := range records[1:] {
+		row := tableRow{values: map[string]string{}}
+		for column, i := range positions {
+			if i >= len(record) {
+				return nil, fmt.Errorf("line %d: missing %s", n+2, column)
+			}
+			row.values[column] = strings.TrimSpace(record[i])
+		}
+
+		if row.latitude, err = strconv.ParseFloat(row.values["latitude"], 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid latitude: %w", n+2, err)
+		}
+		if row.longitude, err = strconv.ParseFloat(row.values["longitude"], 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid longitude: %w", n+2, err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// nameFolder strips accents and spells out abbreviations in place names.
+var nameFolder = strings.NewReplacer(
+	"ä", "a", "ö", "o", "ü", "u", "ß", "ss",
+	"à", "a", "â", "a", "é", "e", "è", "e", "ê", "e", "ë", "e",
+	"î", "i", "ï", "i", "ô", "o", "ù", "u", "û", "u", "ç", "c",
+	"saint ", "st ",
+	"st. ", "st ",
+)
+
+// foldName normalizes a place name for lookups.
+func foldName(name string) string {
+	return nameFolder.Replace(strings.ToLower(strings.TrimSpace(name)))
+}
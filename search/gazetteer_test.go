@@ -0,0 +1,124 @@
+package search_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+func TestDefaultGazetteerCity(t *testing.T) {
+	tests := []struct {
+		name, region string
+		wantRegion   string
+		wantCountry  string
+		wantErr      error
+	}{
+		{name: "Chicago", region: "IL", wantRegion: "IL", wantCountry: "US"},
+		{name: "chicago", wantRegion: "IL", wantCountry: "US"},
+		{name: "Munich", region: "DE", wantRegion: "DE", wantCountry: "DE"},
+		{name: "München", region: "DE", wantRegion: "DE", wantCountry: "DE"},
+		{name: "Köln", region: "DE", wantRegion: "DE", wantCountry: "DE"},
+		{name: "Cologne", wantRegion: "DE", wantCountry: "DE"},
+		{name: "Montréal", region: "QC", wantRegion: "QC", wantCountry: "CA"},
+		{name: "London", region: "UK", wantRegion: "GB", wantCountry: "GB"},
+		{name: "Paris", wantRegion: "FR", wantCountry: "FR"},
+		{name: "Paris", region: "TX", wantRegion: "TX", wantCountry: "US"},
+		{name: "Folsom", region: "CA", wantRegion: "CA", wantCountry: "US"},
+		{name: "El Dorado Hills", region: "CA", wantRegion: "CA", wantCountry: "US"},
+		{name: "Springfield", wantErr: search.ErrAmbiguousPlace},
+		{name: "Springfield", region: "IL", wantRegion: "IL", wantCountry: "US"},
+		{name: "Nowhereville", wantErr: search.ErrUnknownPlace},
+		{name: "Chicago", region: "FR", wantErr: search.ErrUnknownPlace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.region, func(t *testing.T) {
+			place, err := search.DefaultGazetteer().City(tt.name, tt.region)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("City() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if place.Region != tt.wantRegion || place.Country != tt.wantCountry {
+				t.Errorf("City() = %+v, want region %s in %s", place, tt.wantRegion, tt.wantCountry)
+			}
+		})
+	}
+}
+
+func TestGazetteerPostalCode(t *testing.T) {
+	g := search.NewGazetteer()
+	if err := g.LoadPostalCodes(strings.NewReader("code,latitude,longitude\n95630,38.678,-121.1761\n")); err != nil {
+		t.Fatalf("LoadPostalCodes() error = %v", err)
+	}
+
+	tests := []struct {
+		code    string
+		wantErr error
+	}{
+		{code: "95630"},
+		{code: " 95630 "},
+		{code: "95630-1234"},
+		{code: "95762", wantErr: search.ErrUnknownPlace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			place, err := g.PostalCode(tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PostalCode() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && math.Abs(place.Latitude-38.678) > 1e-9 {
+				t.Errorf("PostalCode() = %+v, want 95630", place)
+			}
+		})
+	}
+}
+
+func TestGazetteerLoad(t *testing.T) {
+	zipped := func(s string) string {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(s))
+		zw.Close()
+		return buf.String()
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "csv", data: "code,latitude,longitude\n10002,40.7157,-73.9863\n"},
+		{name: "aliases", data: "zip,lat,lng\n10002,40.7157,-73.9863\n"},
+		{name: "census", data: "GEOID\tALAND\tINTPTLAT\tINTPTLONG                                                                                                               \n10002\t2262837\t40.715\t-73.986\n"},
+		{name: "gzipped", data: zipped("code,latitude,longitude\n10002,40.7157,-73.9863\n")},
+		{name: "missing column", data: "code,latitude\n10002,40.7157\n", wantErr: true},
+		{name: "invalid latitude", data: "code,latitude,longitude\n10002,north,-73.9863\n", wantErr: true},
+		{name: "empty", data: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := search.NewGazetteer()
+			err := g.LoadPostalCodes(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPostalCodes() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if _, err := g.PostalCode("10002"); err != nil {
+				t.Errorf("PostalCode() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+//go:build ignore
+
+// gen_gazetteer downloads the tables embedded in the gazetteer: towns from
+// GeoNames, and every US ZIP code from the Census Bureau's ZCTA gazetteer.
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	townsURL = "https://download.geonames.org/export/dump/cities1000.zip"
+	zctaURL  = "https://www2.census.gov/geo/docs/maps-data/data/gazetteer/2023_Gazetteer/2023_Gaz_zcta_national.zip"
+)
+
+// provinces maps the GeoNames admin1 codes of Canada to abbreviations.
+var provinces = map[string]string{
+	"01": "AB", "02": "BC", "03": "MB", "04": "NB", "05": "NL", "07": "NS",
+	"08": "ON", "09": "PE", "10": "QC", "11": "SK", "12": "YT", "13": "NT", "14": "NU",
+}
+
+var countries = map[string]bool{"US": true, "CA": true, "GB": true, "FR": true, "DE": true}
+
+func main() {
+	if err := generateTowns(filepath.Join("gazetteer", "towns.csv.gz")); err != nil {
+		log.Fatal(err)
+	}
+	if err := generatePostalCodes(filepath.Join("gazetteer", "postal_codes.csv.gz")); err != nil {
+		log.Fatal(err)
+	}
+
+	// the full list replaces the sample
+	if err := os.Remove(filepath.Join("gazetteer", "postal_codes.csv")); err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+}
+
+func generateTowns(path string) error {
+	r, err := download(townsURL)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	// tab separated, see https://download.geonames.org/export/dump/readme.txt
+	var rows [][]string
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		fields := strings.Split(s.Text(), "\t")
+		if len(fields) < 11 || !countries[fields[8]] {
+			continue
+		}
+
+		name, lat, lng, country, region := fields[1], fields[4], fields[5], fields[8], fields[10]
+		switch country {
+		case "US":
+		case "CA":
+			region = provinces[region]
+		default:
+			region = country
+		}
+		if region == "" {
+			continue
+		}
+
+		rows = append(rows, []string{name, region, country, lat, lng})
+	}
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("failed to read towns: %w", err)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if a[2] != b[2] {
+			return a[2] < b[2]
+		}
+		if a[1] != b[1] {
+			return a[1] < b[1]
+		}
+		return a[0] < b[0]
+	})
+
+	return writeTable(path, []string{"name", "region", "country", "latitude", "longitude"}, rows)
+}
+
+func generatePostalCodes(path string) error {
+	r, err := download(zctaURL)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	cr.FieldsPerRecord = -1
+	records, err := cr.ReadAll()
+	if err != nil {
+		return fmt.Errorf("failed to read ZCTAs: %w", err)
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("failed to read ZCTAs: empty file")
+	}
+
+	index := map[string]int{}
+	for i, name := range records[0] {
+		index[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	code, lat, lng := index["GEOID"], index["INTPTLAT"], index["INTPTLONG"]
+
+	var rows [][]string
+	for _, record := range records[1:] {
+		rows = append(rows, []string{
+			strings.TrimSpace(record[code]),
+			strings.TrimSpace(record[lat]),
+			strings.TrimSpace(record[lng]),
+		})
+	}
+
+	return writeTable(path, []string{"code", "latitude", "longitude"}, rows)
+}
+
+// download fetches the zip file at url and opens the one file in it.
+func download(url string) (io.ReadCloser, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", url, err)
+	}
+	for _, f := range zr.File {
+		if strings.HasSuffix(f.Name, ".txt") {
+			return f.Open()
+		}
+	}
+
+	return nil, fmt.Errorf("failed to open %s: no .txt file", url)
+}
+
+// writeTable writes a gzipped CSV file to path.
+func writeTable(path string, header []string, rows [][]string) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	cw := csv.NewWriter(zw)
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
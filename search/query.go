@@ -1,9 +1,15 @@
 package search
 
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
 type Query struct {
 	Latitude           float64  `json:"latitude,omitempty"`
 	Longitude          float64  `json:"longitude,omitempty"`
-	Radius             int      `json:"radius,omitempty"`
+	Radius             int      `json:"radius,omitempty"` // see RadiusUnit
 	RestaurantStatuses []string `json:"restaurantStatuses,omitempty"`
 	ConceptIds         []string `json:"conceptIds,omitempty"`
 	OrderBy            string   `json:"orderBy,omitempty"`
@@ -25,3 +31,33 @@ type Embeds struct {
 	Sustainability bool     `json:"sustainability,omitempty"`
 	Experience     bool     `json:"experience,omitempty"`
 }
+
+// MaxPageSize is the largest page size Validate accepts.
+const MaxPageSize = 4000
+
+// ErrInvalidQuery is matched by the errors returned by Query.Validate.
+var ErrInvalidQuery = errors.New("invalid search query")
+
+// Validate reports every problem with q that would make the API reject it
+// or return nonsense, such as out of range coordinates, a negative radius
+// or an oversized page.
+func (q Query) Validate() error {
+	var errs []error
+	if q.Latitude < -90 || q.Latitude > 90 || math.IsNaN(q.Latitude) {
+		errs = append(errs, fmt.Errorf("%w: latitude %v is not between -90 and 90", ErrInvalidQuery, q.Latitude))
+	}
+	if q.Longitude < -180 || q.Longitude > 180 || math.IsNaN(q.Longitude) {
+		errs = append(errs, fmt.Errorf("%w: longitude %v is not between -180 and 180", ErrInvalidQuery, q.Longitude))
+	}
+	if q.Radius < 0 {
+		errs = append(errs, fmt.Errorf("%w: radius %d is negative", ErrInvalidQuery, q.Radius))
+	}
+	if q.PageSize < 0 || q.PageSize > MaxPageSize {
+		errs = append(errs, fmt.Errorf("%w: page size %d is not between 0 and %d", ErrInvalidQuery, q.PageSize, MaxPageSize))
+	}
+	if q.PageIndex < 0 {
+		errs = append(errs, fmt.Errorf("%w: page index %d is negative", ErrInvalidQuery, q.PageIndex))
+	}
+
+	return errors.Join(errs...)
+}
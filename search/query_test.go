@@ -0,0 +1,54 @@
+package search_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+func TestQueryValidate(t *testing.T) {
+	valid := search.NewQuery().Near(38.67, -121.17).Within(50 * search.Kilometer).PerPage(100)
+
+	tests := []struct {
+		name  string
+		query search.Query
+		// wantErrs is the number of problems reported
+		wantErrs int
+	}{
+		{name: "valid", query: valid},
+		{name: "zero", query: search.Query{}},
+		{name: "edges", query: search.NewQuery().Near(-90, 180).PerPage(search.MaxPageSize)},
+		{name: "latitude out of range", query: valid.Near(90.5, -121.17), wantErrs: 1},
+		{name: "longitude out of range", query: valid.Near(38.67, -181), wantErrs: 1},
+		{name: "NaN latitude", query: valid.Near(math.NaN(), -121.17), wantErrs: 1},
+		{name: "NaN longitude", query: valid.Near(38.67, math.NaN()), wantErrs: 1},
+		{name: "negative radius", query: valid.Within(-search.Kilometer), wantErrs: 1},
+		{name: "oversized page", query: valid.PerPage(search.MaxPageSize + 1), wantErrs: 1},
+		{name: "negative page size", query: valid.PerPage(-1), wantErrs: 1},
+		{name: "negative page index", query: valid.Page(-1), wantErrs: 1},
+		{name: "every problem", query: search.NewQuery().Near(math.NaN(), 200).Within(-search.Meter).PerPage(5000).Page(-2), wantErrs: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.wantErrs == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, search.ErrInvalidQuery) {
+				t.Fatalf("Validate() error = %v, want %v", err, search.ErrInvalidQuery)
+			}
+			// every problem is reported, not just the first
+			joined, ok := err.(interface{ Unwrap() []error })
+			if !ok || len(joined.Unwrap()) != tt.wantErrs {
+				t.Errorf("Validate() error = %v, want %d problems", err, tt.wantErrs)
+			}
+		})
+	}
+}
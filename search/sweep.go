@@ -0,0 +1,296 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+)
+
+// Searcher fetches the single page of results selected by
+// query.PageIndex. *chipotle.Client implements it.
+type Searcher interface {
+	SearchPage(ctx context.Context, query Query) (*Result, error)
+}
+
+// BoundingBox is an area between two latitudes and two longitudes.
+type BoundingBox struct {
+	South, West, North, East float64
+}
+
+// Countries Chipotle operates in, roughly bounded. There are no boxes for
+// states or provinces; to sweep part of a country, pass a BoundingBox of
+// your own.
+var (
+	ContiguousUS = BoundingBox{South: 24.4, West: -125.0, North: 49.4, East: -66.9}
+	Alaska       = BoundingBox{South: 51.2, West: -170.0, North: 71.5, East: -129.9}
+	Hawaii       = BoundingBox{South: 18.9, West: -160.3, North: 22.3, East: -154.8}
+	// Canada is cut off at 60°N, north of which Chipotle has no
+	// restaurants.
+	Canada = BoundingBox{South: 41.7, West: -141.0, North: 60.0, East: -52.6}
+	// UnitedKingdom includes Northern Ireland.
+	UnitedKingdom = BoundingBox{South: 49.8, West: -8.7, North: 60.9, East: 1.8}
+	// France is metropolitan France, including Corsica.
+	France  = BoundingBox{South: 41.3, West: -5.2, North: 51.1, East: 9.6}
+	Germany = BoundingBox{South: 47.2, West: 5.8, North: 55.1, East: 15.1}
+
+	// UnitedStates covers all fifty states.
+	UnitedStates = []BoundingBox{ContiguousUS, Alaska, Hawaii}
+)
+
+// Default sweep settings, used where SweepOptions leaves them unset.
+const (
+	DefaultTileSize         = 200 * Kilometer
+	DefaultSweepConcurrency = 4
+	DefaultSweepDepth       = 6
+)
+
+// SweepOptions configures Sweep.
+type SweepOptions struct {
+	// Regions are the areas to cover. Defaults to UnitedStates.
+	Regions []BoundingBox
+	// TileSize is the side of the square tiles the regions are split
+	// into before any subdivision. Defaults to DefaultTileSize.
+	TileSize Distance
+	// Concurrency bounds the number of tiles searched at once. Defaults
+	// to DefaultSweepConcurrency.
+	Concurrency int
+	// MaxDepth bounds how many times a tile is subdivided. Defaults to
+	// DefaultSweepDepth.
+	MaxDepth int
+}
+
+// Tile is one circular search of a sweep, covering Box.
+type Tile struct {
+	Box       BoundingBox
+	Latitude  float64
+	Longitude float64
+	Radius    Distance
+	// Depth is the number of times the tile's region was subdivided to
+	// get to it.
+	Depth int
+	// Count is the number of restaurants the tile's search returned.
+	Count int
+}
+
+// String implements fmt.Stringer.
+func (t Tile) String() string {
+	return fmt.Sprintf("%.4f,%.4f within %s", t.Latitude, t.Longitude, t.Radius)
+}
+
+// SweepResult is the outcome of Sweep.
+type SweepResult struct {
+	// Restaurants are every restaurant found, once each, ordered by
+	// RestaurantNumber. Their Distance is cleared, as it is relative to
+	// whichever tile found them.
+	Restaurants []restaurant.Restaurant
+	// Tiles is the number of tiles searched.
+	Tiles int
+	// Subdivided are the tiles whose results didn't fit on one page and
+	// were split into smaller tiles.
+	Subdivided []Tile
+	// Saturated are the tiles whose results still didn't fit on one page
+	// at MaxDepth. Restaurants in them may be missing.
+	Saturated []Tile
+}
+
+// Sweep finds every restaurant matching query in opts.Regions, however
+// many there are, by splitting the regions into overlapping circular tiles
+// and fetching the first page of results for each one. The query's
+// coordinates, radius and page index are replaced by each tile's, and its
+// page size defaults to MaxPageSize. Tiles with more than one page of
+// results are split into four and searched again, so no tile relies on the
+// API honoring a large radius or paging through many results. The first
+// failed search stops the sweep.
+func Sweep(ctx context.Context, searcher Searcher, query Query, opts SweepOptions) (*SweepResult, error) {
+	if len(opts.Regions) == 0 {
+		opts.Regions = UnitedStates
+	}
+	if opts.TileSize <= 0 {
+		opts.TileSize = DefaultTileSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultSweepConcurrency
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = MaxPageSize
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultSweepDepth
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s := &sweeper{
+		searcher: searcher,
+		query:    query,
+		opts:     opts,
+		cancel:   cancel,
+		sem:      make(chan struct{}, opts.Concurrency),
+		found:    map[int]restaurant.Restaurant{},
+		result:   &SweepResult{},
+	}
+
+	for _, region := range opts.Regions {
+		for _, box := range region.grid(opts.TileSize) {
+			s.wg.Add(1)
+			go s.search(ctx, newTile(box, 0))
+		}
+	}
+	s.wg.Wait()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	for _, r := range s.found {
+		s.result.Restaurants = append(s.result.Restaurants, r)
+	}
+	sort.Slice(s.result.Restaurants, func(i, j int) bool {
+		return s.result.Restaurants[i].RestaurantNumber < s.result.Restaurants[j].RestaurantNumber
+	})
+
+	return s.result, nil
+}
+
+// sweeper holds the state of a running Sweep.
+type sweeper struct {
+	searcher Searcher
+	query    Query
+	opts     SweepOptions
+	cancel   context.CancelFunc
+	sem      chan struct{}
+	wg       sync.WaitGroup
+
+	mu     sync.Mutex
+	found  map[int]restaurant.Restaurant
+	result *SweepResult
+	err    error
+}
+
+// search searches t, and subdivides it if its results span several pages.
+func (s *sweeper) search(ctx context.Context, t Tile) {
+	defer s.wg.Done()
+
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		s.fail(ctx.Err())
+		return
+	}
+
+	query := s.query.Near(t.Latitude, t.Longitude).Within(t.Radius).Page(0)
+	result, err := s.searcher.SearchPage(ctx, query)
+	<-s.sem
+
+	if err != nil {
+		s.fail(fmt.Errorf("failed to search tile %s: %w", t, err))
+		return
+	}
+
+	t.Count = len(result.Restaurants)
+	truncated := result.PagingInfo.TotalPages > 1 || t.Count < result.PagingInfo.TotalItems
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.result.Tiles++
+	for _, r := range result.Restaurants {
+		r.Distance = 0
+		s.found[r.RestaurantNumber] = r
+	}
+
+	if !truncated {
+		return
+	}
+
+	if t.Depth >= s.opts.MaxDepth {
+		s.result.Saturated = append(s.result.Saturated, t)
+		return
+	}
+
+	s.result.Subdivided = append(s.result.Subdivided, t)
+	for _, box := range t.Box.split() {
+		s.wg.Add(1)
+		go s.search(ctx, newTile(box, t.Depth+1))
+	}
+}
+
+// fail stops the sweep with err, unless it already failed.
+func (s *sweeper) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		s.err = err
+		s.cancel()
+	}
+}
+
+// newTile returns the smallest circle around box.
+func newTile(box BoundingBox, depth int) Tile {
+	t := Tile{
+		Box:       box,
+		Latitude:  (box.South + box.North) / 2,
+		Longitude: (box.West + box.East) / 2,
+		Depth:     depth,
+	}
+
+	// the farthest point of a small box from its center is a corner
+	for _, lat := range []float64{box.South, box.North} {
+		for _, lng := range []float64{box.West, box.East} {
+			t.Radius = max(t.Radius, DistanceBetween(t.Latitude, t.Longitude, lat, lng))
+		}
+	}
+
+	// round up, so rounding the radius to meters can't leave gaps
+	t.Radius = Meters(math.Ceil(t.Radius.Meters()) + 1)
+
+	return t
+}
+
+// grid splits b into rows of boxes no larger than size on a side.
+func (b BoundingBox) grid(size Distance) []BoundingBox {
+	height := DistanceBetween(b.South, b.West, b.North, b.West)
+	rows := max(1, int(math.Ceil(float64(height/size))))
+	rowHeight := (b.North - b.South) / float64(rows)
+
+	var boxes []BoundingBox
+	for row := 0; row < rows; row++ {
+		south := b.South + float64(row)*rowHeight
+		north := south + rowHeight
+
+		// rows are widest on the edge nearest the equator
+		widest := south
+		if math.Abs(north) < math.Abs(south) {
+			widest = north
+		}
+		width := earthRadius * Distance(math.Cos(radians(widest))*radians(b.East-b.West))
+		cols := max(1, int(math.Ceil(float64(width/size))))
+		colWidth := (b.East - b.West) / float64(cols)
+
+		for col := 0; col < cols; col++ {
+			west := b.West + float64(col)*colWidth
+			boxes = append(boxes, BoundingBox{South: south, West: west, North: north, East: west + colWidth})
+		}
+	}
+
+	return boxes
+}
+
+// split divides b into quarters.
+func (b BoundingBox) split() []BoundingBox {
+	lat := (b.South + b.North) / 2
+	lng := (b.West + b.East) / 2
+
+	return []BoundingBox{
+		{South: b.South, West: b.West, North: lat, East: lng},
+		{South: b.South, West: lng, North: lat, East: b.East},
+		{South: lat, West: b.West, North: b.North, East: lng},
+		{South: lat, West: lng, North: b.North, East: b.East},
+	}
+}
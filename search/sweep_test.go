@@ -0,0 +1,180 @@
+package search_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+	"github.com/kylegrantlucas/chipotle-go/restaurant"
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+// grid returns rows*cols open restaurants spread evenly over box, numbered
+// from first.
+func grid(box search.BoundingBox, rows, cols, first int) []restaurant.Restaurant {
+	var restaurants []restaurant.Restaurant
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			restaurants = append(restaurants, restaurant.Restaurant{
+				RestaurantNumber: first + len(restaurants),
+				RestaurantStatus: string(search.StatusOpen),
+				Addresses: []restaurant.Address{{
+					AddressType: string(search.AddressMain),
+					Latitude:    box.South + (float64(row)+0.5)*(box.North-box.South)/float64(rows),
+					Longitude:   box.West + (float64(col)+0.5)*(box.East-box.West)/float64(cols),
+				}},
+			})
+		}
+	}
+
+	return restaurants
+}
+
+// cluster returns n open restaurants at the same spot, numbered from first.
+func cluster(lat, lng float64, n, first int) []restaurant.Restaurant {
+	var restaurants []restaurant.Restaurant
+	for i := 0; i < n; i++ {
+		restaurants = append(restaurants, restaurant.Restaurant{
+			RestaurantNumber: first + i,
+			RestaurantStatus: string(search.StatusOpen),
+			Addresses: []restaurant.Address{{
+				AddressType: string(search.AddressMain),
+				Latitude:    lat,
+				Longitude:   lng,
+			}},
+		})
+	}
+
+	return restaurants
+}
+
+func TestSweep(t *testing.T) {
+	tests := []struct {
+		name           string
+		restaurants    []restaurant.Restaurant
+		pageSize       int
+		maxPageSize    int
+		opts           search.SweepOptions
+		wantFound      int
+		wantSubdivided bool
+		wantSaturated  bool
+	}{
+		{
+			name:        "sparse",
+			restaurants: grid(search.ContiguousUS, 10, 20, 1),
+			pageSize:    100,
+			opts:        search.SweepOptions{Regions: []search.BoundingBox{search.ContiguousUS}, TileSize: 500 * search.Kilometer},
+			wantFound:   200,
+		},
+		{
+			name:           "dense tiles subdivided",
+			restaurants:    grid(search.BoundingBox{South: 40, West: -75, North: 41, East: -74}, 20, 20, 1),
+			pageSize:       50,
+			opts:           search.SweepOptions{Regions: []search.BoundingBox{search.ContiguousUS}, TileSize: 500 * search.Kilometer},
+			wantFound:      400,
+			wantSubdivided: true,
+		},
+		{
+			name:           "page size capped by the server",
+			restaurants:    grid(search.BoundingBox{South: 40, West: -75, North: 41, East: -74}, 20, 20, 1),
+			maxPageSize:    50,
+			opts:           search.SweepOptions{Regions: []search.BoundingBox{search.ContiguousUS}, TileSize: 500 * search.Kilometer},
+			wantFound:      400,
+			wantSubdivided: true,
+		},
+		{
+			name:           "saturated",
+			restaurants:    cluster(40.7, -74, 30, 1),
+			pageSize:       20,
+			opts:           search.SweepOptions{Regions: []search.BoundingBox{search.ContiguousUS}, TileSize: 1000 * search.Kilometer, MaxDepth: 2},
+			wantFound:      20,
+			wantSubdivided: true,
+			wantSaturated:  true,
+		},
+		{
+			name: "several regions",
+			restaurants: append(
+				grid(search.Hawaii, 2, 2, 1),
+				grid(search.Canada, 3, 3, 5)...,
+			),
+			pageSize:  100,
+			opts:      search.SweepOptions{Regions: []search.BoundingBox{search.Hawaii, search.Canada}},
+			wantFound: 13,
+		},
+		{
+			name: "Europe",
+			restaurants: append(append(
+				grid(search.UnitedKingdom, 2, 2, 1),
+				grid(search.France, 2, 2, 5)...),
+				grid(search.Germany, 2, 2, 9)...,
+			),
+			pageSize:  100,
+			opts:      search.SweepOptions{Regions: []search.BoundingBox{search.UnitedKingdom, search.France, search.Germany}},
+			wantFound: 12,
+		},
+		{
+			name:        "outside the regions",
+			restaurants: grid(search.Hawaii, 2, 2, 1),
+			pageSize:    100,
+			opts:        search.SweepOptions{Regions: []search.BoundingBox{search.Alaska}},
+			wantFound:   0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := chipotletest.NewServer()
+			defer s.Close()
+			s.AddRestaurants(tt.restaurants...)
+			s.SetMaxPageSize(tt.maxPageSize)
+			c := chipotle.NewClient("test-key", chipotle.WithBaseURL(s.URL))
+
+			query := search.NewQuery().Statuses(search.StatusOpen).EmbedAll().PerPage(tt.pageSize)
+			result, err := search.Sweep(context.Background(), c, query, tt.opts)
+			if err != nil {
+				t.Fatalf("Sweep() error = %v", err)
+			}
+
+			if got := len(result.Restaurants); got != tt.wantFound {
+				t.Errorf("found %d restaurants, want %d", got, tt.wantFound)
+			}
+			for i, r := range result.Restaurants {
+				if i > 0 && r.RestaurantNumber <= result.Restaurants[i-1].RestaurantNumber {
+					t.Fatalf("restaurants not unique and ordered: %d after %d", r.RestaurantNumber, result.Restaurants[i-1].RestaurantNumber)
+				}
+				if r.Distance != 0 {
+					t.Errorf("restaurant %d has Distance %v, want it cleared", r.RestaurantNumber, r.Distance)
+				}
+			}
+
+			if got := len(result.Subdivided) > 0; got != tt.wantSubdivided {
+				t.Errorf("subdivided = %v, want %v", got, tt.wantSubdivided)
+			}
+			if got := len(result.Saturated) > 0; got != tt.wantSaturated {
+				t.Errorf("saturated = %v, want %v", got, tt.wantSaturated)
+			}
+
+			// one request per tile, never a second page
+			if got := s.Requests(chipotle.EndpointSearch); got != result.Tiles {
+				t.Errorf("requests = %d, want one per tile (%d)", got, result.Tiles)
+			}
+		})
+	}
+}
+
+func TestSweepError(t *testing.T) {
+	s := chipotletest.NewServer()
+	defer s.Close()
+	s.InjectFault(chipotletest.Fault{Endpoint: chipotle.EndpointSearch, StatusCode: http.StatusBadRequest})
+	c := chipotle.NewClient("test-key", chipotle.WithBaseURL(s.URL))
+
+	_, err := search.Sweep(context.Background(), c, search.NewQuery(), search.SweepOptions{})
+
+	var apiErr *chipotle.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Sweep() error = %v, want a 400 APIError", err)
+	}
+}
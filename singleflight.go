@@ -0,0 +1,104 @@
+package chipotle
+
+import (
+	"context"
+	"sync"
+)
+
+// WithDeduplication coalesces concurrent identical requests to endpoints
+// into a single upstream call whose response is shared by every caller.
+// Requests are identical when they have the same endpoint, path, query and
+// body. With no endpoints, requests to every endpoint are deduplicated.
+func WithDeduplication(endpoints ...Endpoint) Option {
+	return func(c *Client) {
+		if c.flights == nil {
+			c.flights = &flightGroup{calls: map[string]*flight{}}
+		}
+
+		if len(endpoints) == 0 {
+			c.dedupeAll = true
+		}
+
+		for _, endpoint := range endpoints {
+			c.dedupeEndpoints[endpoint] = true
+		}
+	}
+}
+
+// dedupes reports whether concurrent requests to endpoint are coalesced.
+func (c *Client) dedupes(endpoint Endpoint) bool {
+	return c.flights != nil && (c.dedupeAll || c.dedupeEndpoints[endpoint])
+}
+
+// flightGroup tracks the upstream calls currently in flight.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is one upstream call and the callers waiting for it.
+type flight struct {
+	done    chan struct{}
+	resp    *Response
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do runs fn once for all concurrent callers with the same key. fn runs
+// with a context that is cancelled only once every waiting caller has
+// given up, so one caller's cancellation doesn't fail the others. Each
+// caller gets its own copy of the response.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*Response, error)) (*Response, error) {
+	g.mu.Lock()
+	f, shared := g.calls[key]
+	if !shared {
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		f = &flight{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = f
+
+		go func() {
+			f.resp, f.err = fn(flightCtx)
+			cancel()
+
+			g.mu.Lock()
+			g.forget(key, f)
+			g.mu.Unlock()
+
+			close(f.done)
+		}()
+	}
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+	case <-ctx.Done():
+		// abandon the call, cancelling it if nobody else is waiting
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			f.cancel()
+			g.forget(key, f)
+		}
+		g.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	resp := *f.resp
+	resp.Shared = shared
+
+	return &resp, nil
+}
+
+// forget stops new callers from joining f. g.mu must be held.
+func (g *flightGroup) forget(key string, f *flight) {
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,105 @@
+package chipotle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kylegrantlucas/chipotle-go"
+)
+
+func TestWithDeduplication(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         []chipotle.Option
+		restaurants  []int
+		wantRequests int
+	}{
+		{
+			name:         "disabled",
+			restaurants:  []int{1, 1, 1, 1},
+			wantRequests: 4,
+		},
+		{
+			name:         "identical requests",
+			opts:         []chipotle.Option{chipotle.WithDeduplication()},
+			restaurants:  []int{1, 1, 1, 1},
+			wantRequests: 1,
+		},
+		{
+			name:         "different requests",
+			opts:         []chipotle.Option{chipotle.WithDeduplication()},
+			restaurants:  []int{1, 2, 1, 2},
+			wantRequests: 2,
+		},
+		{
+			name:         "other endpoint",
+			opts:         []chipotle.Option{chipotle.WithDeduplication(chipotle.EndpointSearch)},
+			restaurants:  []int{1, 1, 1, 1},
+			wantRequests: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newServer(t, 2)
+			// keep the first request in flight while the others arrive
+			s.SetLatency(50 * time.Millisecond)
+			c := newClient(s, tt.opts...)
+
+			var wg sync.WaitGroup
+			for _, id := range tt.restaurants {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					m, err := c.GetMenuContext(context.Background(), id)
+					if err != nil {
+						t.Errorf("GetMenuContext(%d) error = %v", id, err)
+						return
+					}
+					if m.RestaurantID != id {
+						t.Errorf("GetMenuContext(%d) returned the menu of %d", id, m.RestaurantID)
+					}
+				}()
+			}
+			wg.Wait()
+
+			if got := s.Requests(chipotle.EndpointMenu); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestWithDeduplicationCancel(t *testing.T) {
+	s := newServer(t, 1)
+	s.SetLatency(50 * time.Millisecond)
+	c := newClient(s, chipotle.WithDeduplication())
+
+	// one caller gives up early; the other must still get the menu
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if _, err := c.GetMenuContext(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("GetMenuContext() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+			t.Errorf("GetMenuContext() error = %v", err)
+		}
+	}()
+	wg.Wait()
+
+	if got := s.Requests(chipotle.EndpointMenu); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
@@ -0,0 +1,180 @@
+package chipotle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kylegrantlucas/chipotle-go/menu"
+	"github.com/kylegrantlucas/chipotle-go/search"
+)
+
+// instrumentationName identifies the library to OpenTelemetry.
+const instrumentationName = "github.com/kylegrantlucas/chipotle-go"
+
+// telemetry records spans and metrics for API calls. A nil *telemetry
+// records nothing, so clients without telemetry pay nothing for it.
+type telemetry struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	retries  metric.Int64Counter
+}
+
+// WithTracerProvider records a span for every API call, one per page for
+// searches, using tp.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Client) {
+		c.telemetry().tracer = tp.Tracer(instrumentationName)
+	}
+}
+
+// WithMeterProvider records request latency, errors by status code and
+// retries using mp. Instruments that fail to register are skipped.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *Client) {
+		t := c.telemetry()
+		meter := mp.Meter(instrumentationName)
+
+		t.duration, _ = meter.Float64Histogram("chipotle.client.request.duration",
+			metric.WithUnit("s"),
+			metric.WithDescription("Duration of API calls, including retries."))
+		t.errors, _ = meter.Int64Counter("chipotle.client.request.errors",
+			metric.WithDescription("API calls that failed, by endpoint and status code."))
+		t.retries, _ = meter.Int64Counter("chipotle.client.request.retries",
+			metric.WithDescription("HTTP requests retried after a failed attempt."))
+	}
+}
+
+// telemetry returns the client's telemetry, creating it if needed.
+func (c *Client) telemetry() *telemetry {
+	if c.tel == nil {
+		c.tel = &telemetry{}
+	}
+
+	return c.tel
+}
+
+// middleware returns the Middleware that instruments calls.
+func (t *telemetry) middleware(next Handler) Handler {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		attrs := []attribute.KeyValue{attribute.String("chipotle.endpoint", string(req.Endpoint))}
+
+		span := trace.SpanFromContext(ctx)
+		if t.tracer != nil {
+			ctx, span = t.tracer.Start(ctx, "chipotle."+string(req.Endpoint),
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(requestAttributes(req)...))
+			defer span.End()
+		}
+
+		start := time.Now()
+		resp, err := next(ctx, req)
+		elapsed := time.Since(start)
+
+		statusCode := 0
+		if resp != nil {
+			statusCode = resp.StatusCode
+		}
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			statusCode = apiErr.StatusCode
+		}
+
+		// an unchanged menu is a successful request
+		failed := err != nil
+		if errors.Is(err, ErrNotModified) {
+			statusCode, failed = http.StatusNotModified, false
+		}
+
+		if statusCode != 0 {
+			attrs = append(attrs, attribute.Int("http.response.status_code", statusCode))
+		}
+
+		if t.tracer != nil {
+			if statusCode != 0 {
+				span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+			}
+
+			if failed {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			} else if err == nil {
+				span.SetAttributes(responseAttributes(resp)...)
+			}
+		}
+
+		if t.duration != nil {
+			t.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(attrs...))
+		}
+
+		if failed && t.errors != nil {
+			t.errors.Add(ctx, 1, metric.WithAttributes(attrs...))
+		}
+
+		return resp, err
+	}
+}
+
+// recordRetry counts a retry of a request to endpoint.
+func (t *telemetry) recordRetry(ctx context.Context, endpoint Endpoint, statusCode int) {
+	if t == nil || t.retries == nil {
+		return
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("chipotle.endpoint", string(endpoint))}
+	if statusCode != 0 {
+		attrs = append(attrs, attribute.Int("http.response.status_code", statusCode))
+	}
+
+	t.retries.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+func requestAttributes(req *Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.String("chipotle.endpoint", string(req.Endpoint)),
+		attribute.String("http.request.method", req.Method),
+	}
+
+	if req.Query != nil {
+		attrs = append(attrs, attribute.Int("chipotle.search.page_index", req.Query.PageIndex))
+	}
+
+	if req.Endpoint == EndpointMenu {
+		attrs = append(attrs, attribute.Int("chipotle.restaurant_id", req.RestaurantID))
+	}
+
+	return attrs
+}
+
+func responseAttributes(resp *Response) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		attribute.Bool("chipotle.cached", resp.Cached),
+		attribute.Int("chipotle.attempts", resp.Attempts),
+	}
+
+	switch result := resp.Result.(type) {
+	case *search.Result:
+		attrs = append(attrs,
+			attribute.Int("chipotle.search.current_page", result.PagingInfo.CurrentPage),
+			attribute.Int("chipotle.search.total_pages", result.PagingInfo.TotalPages),
+			attribute.Int("chipotle.search.restaurant_count", len(result.Restaurants)),
+		)
+	case *menu.Menu:
+		attrs = append(attrs,
+			attribute.Int("chipotle.menu.entree_count", len(result.Entrees)),
+			attribute.Int("chipotle.menu.side_count", len(result.Sides)),
+			attribute.Int("chipotle.menu.drink_count", len(result.Drinks)),
+			attribute.Int("chipotle.menu.non_food_item_count", len(result.NonFoodItems)),
+		)
+	}
+
+	return attrs
+}
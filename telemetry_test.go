@@ -0,0 +1,186 @@
+package chipotle_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/kylegrantlucas/chipotle-go"
+	"github.com/kylegrantlucas/chipotle-go/chipotletest"
+)
+
+func TestWithTracerProvider(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(c *chipotle.Client) error
+		wantName   string
+		wantStatus codes.Code
+		wantAttrs  []attribute.KeyValue
+	}{
+		{
+			name: "menu",
+			call: func(c *chipotle.Client) error {
+				_, err := c.GetMenuContext(context.Background(), 1)
+				return err
+			},
+			wantName:   "chipotle.menu",
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("chipotle.endpoint", "menu"),
+				attribute.String("http.request.method", "GET"),
+				attribute.Int("chipotle.restaurant_id", 1),
+				attribute.Int("http.response.status_code", http.StatusOK),
+				attribute.Bool("chipotle.cached", false),
+				attribute.Int("chipotle.attempts", 1),
+				attribute.Int("chipotle.menu.side_count", 1),
+			},
+		},
+		{
+			name: "missing menu",
+			call: func(c *chipotle.Client) error {
+				_, err := c.GetMenuContext(context.Background(), 404)
+				return err
+			},
+			wantName:   "chipotle.menu",
+			wantStatus: codes.Error,
+			wantAttrs: []attribute.KeyValue{
+				attribute.Int("chipotle.restaurant_id", 404),
+				attribute.Int("http.response.status_code", http.StatusNotFound),
+			},
+		},
+		{
+			name: "search",
+			call: func(c *chipotle.Client) error {
+				_, err := c.SearchPage(context.Background(), nearby())
+				return err
+			},
+			wantName:   "chipotle.search",
+			wantStatus: codes.Unset,
+			wantAttrs: []attribute.KeyValue{
+				attribute.String("chipotle.endpoint", "search"),
+				attribute.String("http.request.method", "POST"),
+				attribute.Int("chipotle.search.current_page", 1),
+				attribute.Int("chipotle.search.total_pages", 1),
+				attribute.Int("chipotle.search.restaurant_count", 2),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+			c := newClient(newServer(t, 2), chipotle.WithTracerProvider(tp))
+
+			if err := tt.call(c); (err != nil) != (tt.wantStatus == codes.Error) {
+				t.Fatalf("call error = %v", err)
+			}
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+
+			if span.Name != tt.wantName || span.SpanKind != trace.SpanKindClient {
+				t.Errorf("span = %s (%v), want %s (client)", span.Name, span.SpanKind, tt.wantName)
+			}
+			if span.Status.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", span.Status.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == codes.Error && len(span.Events) == 0 {
+				t.Error("no error recorded on the span")
+			}
+
+			got := attribute.NewSet(span.Attributes...)
+			for _, want := range tt.wantAttrs {
+				if value, ok := got.Value(want.Key); !ok || value != want.Value {
+					t.Errorf("attribute %s = %v, want %v", want.Key, value.Emit(), want.Value.Emit())
+				}
+			}
+		})
+	}
+}
+
+func TestWithMeterProvider(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	s := newServer(t, 1)
+	s.InjectFault(chipotletest.Fault{Endpoint: chipotle.EndpointMenu, StatusCode: http.StatusServiceUnavailable, Times: 1})
+	c := newClient(s, chipotle.WithMeterProvider(mp))
+
+	// a retried success and a failure
+	if _, err := c.GetMenuContext(context.Background(), 1); err != nil {
+		t.Fatalf("GetMenuContext() error = %v", err)
+	}
+	if _, err := c.GetMenuContext(context.Background(), 404); err == nil {
+		t.Fatal("GetMenuContext() error = nil, want a 404")
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	withStatus := func(code int) attribute.Set {
+		return attribute.NewSet(attribute.String("chipotle.endpoint", "menu"), attribute.Int("http.response.status_code", code))
+	}
+
+	tests := []struct {
+		metric string
+		attrs  attribute.Set
+		want   uint64
+	}{
+		{metric: "chipotle.client.request.duration", attrs: withStatus(http.StatusOK), want: 1},
+		{metric: "chipotle.client.request.duration", attrs: withStatus(http.StatusNotFound), want: 1},
+		{metric: "chipotle.client.request.errors", attrs: withStatus(http.StatusNotFound), want: 1},
+		{metric: "chipotle.client.request.errors", attrs: withStatus(http.StatusOK), want: 0},
+		{metric: "chipotle.client.request.retries", attrs: withStatus(http.StatusServiceUnavailable), want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric+" "+tt.attrs.Encoded(attribute.DefaultEncoder()), func(t *testing.T) {
+			if got := count(rm, tt.metric, tt.attrs); got != tt.want {
+				t.Errorf("count = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// count returns the number of measurements of the named counter or
+// histogram with attrs.
+func count(rm metricdata.ResourceMetrics, name string, attrs attribute.Set) uint64 {
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					if dp.Attributes.Equals(&attrs) {
+						return uint64(dp.Value)
+					}
+				}
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					if dp.Attributes.Equals(&attrs) {
+						return dp.Count
+					}
+				}
+			}
+		}
+	}
+
+	return 0
+}